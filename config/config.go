@@ -2,13 +2,22 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+// groupNamePattern matches group names that are safe as a segment of the
+// group's endpoint path and as an http.ServeMux pattern
+var groupNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
 type Config struct {
 	Gateway    GatewayConfig    `yaml:"gateway" mapstructure:"gateway"`
 	Groups     []Group          `yaml:"groups" mapstructure:"groups"`
@@ -16,48 +25,207 @@ type Config struct {
 }
 
 type GatewayConfig struct {
-	Host     string        `yaml:"host" mapstructure:"host"`
-	Port     int           `yaml:"port" mapstructure:"port"`
-	Endpoint string        `yaml:"endpoint" mapstructure:"endpoint"`
-	Timeout  time.Duration `yaml:"timeout" mapstructure:"timeout"`
+	Host     string `yaml:"host" mapstructure:"host"`
+	Port     int    `yaml:"port" mapstructure:"port"`
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+	// Timeout bounds each request to a backend unless the backend or the
+	// tool overrides it
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
+	// RefreshInterval is how often backend capabilities are re-discovered;
+	// zero disables periodic refreshes
+	RefreshInterval time.Duration `yaml:"refresh_interval" mapstructure:"refresh_interval"`
+	// Namespace is the default tool and prompt namespacing strategy for all groups
+	Namespace string `yaml:"namespace" mapstructure:"namespace"`
+	// PageSize is the number of items per page of the gateway's resource,
+	// template and prompt lists and of list_tools; zero uses the SDK default.
+	// Resource and prompt pages are cut before hidden items are removed, so
+	// they may hold fewer items.
+	PageSize int `yaml:"page_size" mapstructure:"page_size"`
 }
 
+// Tool and prompt namespacing strategies
+const (
+	// NamespaceNone exposes tools under their original names
+	NamespaceNone = "none"
+	// NamespaceBackend exposes tools as "backend.tool"
+	NamespaceBackend = "backend"
+	// NamespaceGroup exposes tools as "group/backend/tool"
+	NamespaceGroup = "group"
+)
+
 type Group struct {
-	Name     string             `yaml:"name" mapstructure:"name"`
+	Name string `yaml:"name" mapstructure:"name"`
+	// Namespace overrides the gateway's tool namespacing strategy
+	Namespace string `yaml:"namespace,omitempty" mapstructure:"namespace"`
+	// Access restricts the group to some clients; without it any
+	// authenticated client may use the group
+	Access   AccessPolicy       `yaml:"access,omitempty" mapstructure:"access"`
 	Backends map[string]Backend `yaml:"backends" mapstructure:"backends"`
 }
 
+// AccessPolicy lists the clients allowed to see and call a group's tools. A
+// client is allowed if its name is one of Users, it has one of Roles, or one
+// of its token claims has a value listed in Claims.
+type AccessPolicy struct {
+	Users  []string     `yaml:"users,omitempty" mapstructure:"users"`
+	Roles  []string     `yaml:"roles,omitempty" mapstructure:"roles"`
+	Claims []ClaimMatch `yaml:"claims,omitempty" mapstructure:"claims"`
+}
+
+// ClaimMatch matches JWTs whose claim, a string or an array of strings,
+// contains one of Values
+type ClaimMatch struct {
+	Claim  string   `yaml:"claim" mapstructure:"claim"`
+	Values []string `yaml:"values" mapstructure:"values"`
+}
+
+// Restricted reports whether the policy limits access to the group
+func (p AccessPolicy) Restricted() bool {
+	return len(p.Users) > 0 || len(p.Roles) > 0 || len(p.Claims) > 0
+}
+
 type Backend struct {
-	Name      string            `yaml:"name" mapstructure:"name"`
-	Transport string            `yaml:"transport" mapstructure:"transport"`
-	Command   string            `yaml:"command,omitempty" mapstructure:"command"`
-	Args      []string          `yaml:"args,omitempty" mapstructure:"args"`
-	Endpoint  string            `yaml:"endpoint,omitempty" mapstructure:"endpoint"`
-	Headers   map[string]string `yaml:"headers,omitempty" mapstructure:"headers"`
-	Env       map[string]string `yaml:"env,omitempty" mapstructure:"env"`
+	Name        string            `yaml:"name" mapstructure:"name"`
+	Transport   string            `yaml:"transport" mapstructure:"transport"`
+	Command     string            `yaml:"command,omitempty" mapstructure:"command"`
+	Args        []string          `yaml:"args,omitempty" mapstructure:"args"`
+	Endpoint    string            `yaml:"endpoint,omitempty" mapstructure:"endpoint"`
+	Headers     map[string]string `yaml:"headers,omitempty" mapstructure:"headers"`
+	Env         map[string]string `yaml:"env,omitempty" mapstructure:"env"`
+	Restart     RestartConfig     `yaml:"restart,omitempty" mapstructure:"restart"`
+	HealthCheck HealthCheckConfig `yaml:"health_check,omitempty" mapstructure:"health_check"`
+	// Include and Exclude are glob patterns selecting which of the backend's
+	// tools are exposed. Without Include every tool not excluded is exposed.
+	Include []string `yaml:"include,omitempty" mapstructure:"include"`
+	Exclude []string `yaml:"exclude,omitempty" mapstructure:"exclude"`
+	// Tools changes how individual tools of the backend are presented
+	Tools []ToolOverride `yaml:"tools,omitempty" mapstructure:"tools"`
+	// Timeout overrides the gateway's request timeout for this backend
+	Timeout time.Duration `yaml:"timeout,omitempty" mapstructure:"timeout"`
+}
+
+// ToolOverride renames a backend tool, adds aliases for it, rewrites its
+// description or supplies default argument values. Tool is the tool's name
+// on the backend; the namespacing strategy applies to Name and Aliases.
+type ToolOverride struct {
+	Tool        string   `yaml:"tool" mapstructure:"tool"`
+	Name        string   `yaml:"name,omitempty" mapstructure:"name"`
+	Aliases     []string `yaml:"aliases,omitempty" mapstructure:"aliases"`
+	Description string   `yaml:"description,omitempty" mapstructure:"description"`
+	// Defaults are passed to the backend for arguments the client leaves
+	// out. They are a list because configuration map keys lose their case.
+	Defaults []ArgumentDefault `yaml:"defaults,omitempty" mapstructure:"defaults"`
+	// Cache marks the tool as idempotent, so that its results are cached
+	// when caching is enabled
+	Cache bool `yaml:"cache,omitempty" mapstructure:"cache"`
+	// Timeout overrides the backend's request timeout for calls of the tool
+	Timeout time.Duration `yaml:"timeout,omitempty" mapstructure:"timeout"`
+}
+
+// ArgumentDefault is the default value of a tool argument
+type ArgumentDefault struct {
+	Argument string      `yaml:"argument" mapstructure:"argument"`
+	Value    interface{} `yaml:"value" mapstructure:"value"`
+}
+
+// PublicName returns the name the tool is exposed under before namespacing
+func (o ToolOverride) PublicName() string {
+	if o.Name != "" {
+		return o.Name
+	}
+	return o.Tool
+}
+
+// RestartConfig controls how a crashed stdio backend process is restarted.
+// Zero values fall back to the gateway defaults.
+type RestartConfig struct {
+	// MaxRestarts is the number of consecutive restarts attempted before
+	// giving up; a negative value disables restarts
+	MaxRestarts int           `yaml:"max_restarts,omitempty" mapstructure:"max_restarts"`
+	Backoff     time.Duration `yaml:"backoff,omitempty" mapstructure:"backoff"`
+	MaxBackoff  time.Duration `yaml:"max_backoff,omitempty" mapstructure:"max_backoff"`
+}
+
+// HealthCheckConfig controls the periodic ping used to detect whether a
+// backend is up. Zero values fall back to the gateway defaults.
+type HealthCheckConfig struct {
+	// Interval between checks; a negative value disables health checks
+	Interval time.Duration `yaml:"interval,omitempty" mapstructure:"interval"`
+	Timeout  time.Duration `yaml:"timeout,omitempty" mapstructure:"timeout"`
+	// HealthyThreshold is the number of consecutive successful checks
+	// needed to mark a down backend up again
+	HealthyThreshold int `yaml:"healthy_threshold,omitempty" mapstructure:"healthy_threshold"`
+	// UnhealthyThreshold is the number of consecutive failed checks
+	// needed to mark an up backend down
+	UnhealthyThreshold int `yaml:"unhealthy_threshold,omitempty" mapstructure:"unhealthy_threshold"`
 }
 
 type MiddlewareConfig struct {
 	Logging LoggingConfig `yaml:"logging" mapstructure:"logging"`
 	CORS    CORSConfig    `yaml:"cors" mapstructure:"cors"`
 	Caching CachingConfig `yaml:"caching" mapstructure:"caching"`
+	Auth    AuthConfig    `yaml:"auth" mapstructure:"auth"`
 }
 
+// LoggingConfig configures the gateway's log output
 type LoggingConfig struct {
-	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
-	Level   string `yaml:"level" mapstructure:"level"`
+	// Enabled false only logs errors
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Level is one of debug, info, warn or error
+	Level string `yaml:"level" mapstructure:"level"`
+	// Format is LogFormatText or LogFormatJSON
+	Format string `yaml:"format" mapstructure:"format"`
+	// Output is a file path, "stdout" or "stderr"
+	Output string `yaml:"output,omitempty" mapstructure:"output"`
 }
 
+// Log formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// CORSConfig configures which browser origins may call the gateway
 type CORSConfig struct {
-	Enabled        bool     `yaml:"enabled" mapstructure:"enabled"`
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// AllowedOrigins lists origins such as "https://app.example.com"; "*"
+	// allows any origin. See Config.CORSOrigins for the default.
 	AllowedOrigins []string `yaml:"allowed_origins" mapstructure:"allowed_origins"`
 }
 
+// CachingConfig configures caching of backend responses: tool lists always,
+// tool call results only for tools marked as cacheable
 type CachingConfig struct {
 	Enabled bool          `yaml:"enabled" mapstructure:"enabled"`
 	TTL     time.Duration `yaml:"ttl" mapstructure:"ttl"`
 }
 
+// AuthConfig configures authentication of clients connecting to the gateway.
+// Clients present a bearer token that is either one of the static API keys
+// or a JWT signed by a key of the JWKS file.
+type AuthConfig struct {
+	Enabled bool           `yaml:"enabled" mapstructure:"enabled"`
+	APIKeys []APIKeyConfig `yaml:"api_keys,omitempty" mapstructure:"api_keys"`
+	JWT     JWTConfig      `yaml:"jwt,omitempty" mapstructure:"jwt"`
+}
+
+// APIKeyConfig is a static bearer token and the client identity it grants
+type APIKeyConfig struct {
+	Name  string   `yaml:"name" mapstructure:"name"`
+	Key   string   `yaml:"key" mapstructure:"key"`
+	Roles []string `yaml:"roles,omitempty" mapstructure:"roles"`
+}
+
+// JWTConfig configures validation of JWT bearer tokens
+type JWTConfig struct {
+	JWKSFile string `yaml:"jwks_file,omitempty" mapstructure:"jwks_file"`
+	// Issuer and Audience, when set, must match the iss and aud claims
+	Issuer   string `yaml:"issuer,omitempty" mapstructure:"issuer"`
+	Audience string `yaml:"audience,omitempty" mapstructure:"audience"`
+	// RolesClaim names the claim holding the client's roles
+	RolesClaim string `yaml:"roles_claim,omitempty" mapstructure:"roles_claim"`
+}
+
 func LoadConfig(configPath string) (*Config, error) {
 	v := viper.New()
 
@@ -85,7 +253,7 @@ func LoadConfig(configPath string) (*Config, error) {
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			// 設定ファイルが見つからない場合はデフォルト値を使用
-			fmt.Printf("Warning: Config file not found, using defaults\n")
+			slog.Warn("Config file not found, using defaults")
 		} else {
 			return nil, fmt.Errorf("failed to read config file: %w", err)
 		}
@@ -113,14 +281,18 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("gateway.port", 8080)
 	v.SetDefault("gateway.endpoint", "/mcp")
 	v.SetDefault("gateway.timeout", "30s")
+	v.SetDefault("gateway.refresh_interval", "5m")
+	v.SetDefault("gateway.namespace", NamespaceNone)
 
 	// Middleware defaults
 	v.SetDefault("middleware.logging.enabled", true)
 	v.SetDefault("middleware.logging.level", "info")
+	v.SetDefault("middleware.logging.format", LogFormatText)
 	v.SetDefault("middleware.cors.enabled", true)
-	v.SetDefault("middleware.cors.allowed_origins", []string{"*"})
 	v.SetDefault("middleware.caching.enabled", true)
 	v.SetDefault("middleware.caching.ttl", "300s")
+	v.SetDefault("middleware.auth.enabled", false)
+	v.SetDefault("middleware.auth.jwt.roles_claim", "roles")
 }
 
 func expandConfigEnvVars(config *Config) {
@@ -150,6 +322,12 @@ func expandConfigEnvVars(config *Config) {
 			config.Groups[i].Backends[name] = backend
 		}
 	}
+
+	// APIキーとJWKSファイルのパスを展開
+	for i := range config.Middleware.Auth.APIKeys {
+		config.Middleware.Auth.APIKeys[i].Key = os.ExpandEnv(config.Middleware.Auth.APIKeys[i].Key)
+	}
+	config.Middleware.Auth.JWT.JWKSFile = os.ExpandEnv(config.Middleware.Auth.JWT.JWKSFile)
 }
 
 func validateConfig(config *Config) error {
@@ -162,6 +340,34 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("gateway endpoint cannot be empty")
 	}
 
+	if config.Gateway.Timeout < 0 {
+		return fmt.Errorf("gateway timeout cannot be negative")
+	}
+
+	if config.Gateway.RefreshInterval < 0 {
+		return fmt.Errorf("refresh interval cannot be negative")
+	}
+
+	if config.Gateway.PageSize < 0 {
+		return fmt.Errorf("page size cannot be negative")
+	}
+
+	if err := validateNamespace(config.Gateway.Namespace); err != nil {
+		return err
+	}
+
+	if err := validateLogging(&config.Middleware.Logging); err != nil {
+		return err
+	}
+
+	if config.Middleware.Caching.Enabled && config.Middleware.Caching.TTL <= 0 {
+		return fmt.Errorf("caching ttl must be positive")
+	}
+
+	if err := validateAuth(&config.Middleware.Auth); err != nil {
+		return err
+	}
+
 	// Groups設定の検証
 	if len(config.Groups) == 0 {
 		return fmt.Errorf("at least one group must be defined")
@@ -173,11 +379,24 @@ func validateConfig(config *Config) error {
 			return fmt.Errorf("group name cannot be empty")
 		}
 
+		// グループ名は /mcp/<group> のようにURLパスに使われる
+		if !groupNamePattern.MatchString(group.Name) || group.Name == "." || group.Name == ".." {
+			return fmt.Errorf("group name %q may only contain letters, digits, '.', '_' and '-'", group.Name)
+		}
+
 		if groupNames[group.Name] {
 			return fmt.Errorf("duplicate group name: %s", group.Name)
 		}
 		groupNames[group.Name] = true
 
+		if err := validateNamespace(group.Namespace); err != nil {
+			return fmt.Errorf("%w in group %s", err, group.Name)
+		}
+
+		if err := validateAccessPolicy(group.Access, config.Middleware.Auth.Enabled); err != nil {
+			return fmt.Errorf("%w in group %s", err, group.Name)
+		}
+
 		if len(group.Backends) == 0 {
 			return fmt.Errorf("group %s must have at least one backend", group.Name)
 		}
@@ -209,17 +428,195 @@ func validateBackend(backend *Backend, groupName string) error {
 		if backend.Command == "" {
 			return fmt.Errorf("command is required for stdio transport in backend %s (group %s)", backend.Name, groupName)
 		}
-	case "http":
+		if backend.Restart.Backoff < 0 || backend.Restart.MaxBackoff < 0 {
+			return fmt.Errorf("restart backoff cannot be negative in backend %s (group %s)", backend.Name, groupName)
+		}
+	case "http", "sse", "websocket":
 		if backend.Endpoint == "" {
-			return fmt.Errorf("endpoint is required for http transport in backend %s (group %s)", backend.Name, groupName)
+			return fmt.Errorf("endpoint is required for %s transport in backend %s (group %s)", backend.Transport, backend.Name, groupName)
 		}
 	default:
 		return fmt.Errorf("unsupported transport type %s in backend %s (group %s)", backend.Transport, backend.Name, groupName)
 	}
 
+	if backend.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative in backend %s (group %s)", backend.Name, groupName)
+	}
+
+	healthCheck := backend.HealthCheck
+	if healthCheck.Timeout < 0 || healthCheck.HealthyThreshold < 0 || healthCheck.UnhealthyThreshold < 0 {
+		return fmt.Errorf("health check timeout and thresholds cannot be negative in backend %s (group %s)", backend.Name, groupName)
+	}
+
+	for _, pattern := range append(slices.Clone(backend.Include), backend.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q in backend %s (group %s): %w", pattern, backend.Name, groupName, err)
+		}
+	}
+
+	if err := validateToolOverrides(backend.Tools); err != nil {
+		return fmt.Errorf("%w in backend %s (group %s)", err, backend.Name, groupName)
+	}
+
 	return nil
 }
 
+// validateToolOverrides checks that every override names a tool once and
+// that no two overrides expose the same name
+func validateToolOverrides(overrides []ToolOverride) error {
+	tools := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, override := range overrides {
+		if override.Tool == "" {
+			return fmt.Errorf("tool override must name a tool")
+		}
+		if tools[override.Tool] {
+			return fmt.Errorf("duplicate override for tool %s", override.Tool)
+		}
+		if override.Timeout < 0 {
+			return fmt.Errorf("timeout of tool %s cannot be negative", override.Tool)
+		}
+		tools[override.Tool] = true
+
+		for _, name := range append([]string{override.PublicName()}, override.Aliases...) {
+			if name == "" {
+				return fmt.Errorf("alias of tool %s cannot be empty", override.Tool)
+			}
+			if names[name] {
+				return fmt.Errorf("tool name %s is used by more than one override", name)
+			}
+			names[name] = true
+		}
+
+		arguments := make(map[string]bool)
+		for _, argDefault := range override.Defaults {
+			if argDefault.Argument == "" {
+				return fmt.Errorf("default argument name cannot be empty for tool %s", override.Tool)
+			}
+			if arguments[argDefault.Argument] {
+				return fmt.Errorf("duplicate default for argument %s of tool %s", argDefault.Argument, override.Tool)
+			}
+			arguments[argDefault.Argument] = true
+		}
+	}
+	return nil
+}
+
+// validateNamespace checks a tool namespacing strategy; empty means inherited
+func validateNamespace(namespace string) error {
+	switch namespace {
+	case "", NamespaceNone, NamespaceBackend, NamespaceGroup:
+		return nil
+	default:
+		return fmt.Errorf("unsupported namespace strategy %s", namespace)
+	}
+}
+
+// validateLogging checks the log level and format; empty means the default
+func validateLogging(logging *LoggingConfig) error {
+	switch strings.ToLower(logging.Level) {
+	case "", "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("unsupported log level %s", logging.Level)
+	}
+
+	switch strings.ToLower(logging.Format) {
+	case "", LogFormatText, LogFormatJSON:
+	default:
+		return fmt.Errorf("unsupported log format %s", logging.Format)
+	}
+
+	return nil
+}
+
+// validateAuth checks the client authentication settings
+func validateAuth(auth *AuthConfig) error {
+	if !auth.Enabled {
+		return nil
+	}
+
+	if len(auth.APIKeys) == 0 && auth.JWT.JWKSFile == "" {
+		return fmt.Errorf("auth requires at least one API key or a JWKS file")
+	}
+
+	names := make(map[string]bool)
+	keys := make(map[string]bool)
+	for _, apiKey := range auth.APIKeys {
+		if apiKey.Name == "" {
+			return fmt.Errorf("API key name cannot be empty")
+		}
+		if apiKey.Key == "" {
+			return fmt.Errorf("API key %s cannot be empty", apiKey.Name)
+		}
+		if names[apiKey.Name] {
+			return fmt.Errorf("duplicate API key name: %s", apiKey.Name)
+		}
+		if keys[apiKey.Key] {
+			return fmt.Errorf("API key %s reuses the key of another client", apiKey.Name)
+		}
+		names[apiKey.Name] = true
+		keys[apiKey.Key] = true
+	}
+
+	return nil
+}
+
+// validateAccessPolicy checks a group's access policy. Clients can only be
+// told apart when they authenticate, so a policy requires authentication.
+func validateAccessPolicy(policy AccessPolicy, authEnabled bool) error {
+	if !policy.Restricted() {
+		return nil
+	}
+
+	if !authEnabled {
+		return fmt.Errorf("access policy requires auth to be enabled")
+	}
+
+	for _, match := range policy.Claims {
+		if match.Claim == "" {
+			return fmt.Errorf("access policy claim name cannot be empty")
+		}
+		if len(match.Values) == 0 {
+			return fmt.Errorf("access policy claim %s must list at least one value", match.Claim)
+		}
+	}
+
+	return nil
+}
+
+// NamespaceFor returns the tool namespacing strategy that applies to a group
+func (c *Config) NamespaceFor(group Group) string {
+	if group.Namespace != "" {
+		return group.Namespace
+	}
+	if c.Gateway.Namespace != "" {
+		return c.Gateway.Namespace
+	}
+	return NamespaceNone
+}
+
+// TimeoutFor returns the request timeout that applies to a backend
+func (c *Config) TimeoutFor(backend Backend) time.Duration {
+	if backend.Timeout > 0 {
+		return backend.Timeout
+	}
+	return c.Gateway.Timeout
+}
+
+// CORSOrigins returns the origins allowed to make cross-origin requests.
+// Without configured origins any origin is allowed, unless clients must
+// authenticate: then only same-origin requests are allowed, so that a
+// malicious page cannot use credentials a user's browser holds.
+func (c *Config) CORSOrigins() []string {
+	if len(c.Middleware.CORS.AllowedOrigins) > 0 {
+		return c.Middleware.CORS.AllowedOrigins
+	}
+	if c.Middleware.Auth.Enabled {
+		return nil
+	}
+	return []string{"*"}
+}
+
 // GetConfigPath returns the path to the config file being used
 func GetConfigPath(configPath string) (string, error) {
 	if configPath != "" {
@@ -134,6 +134,9 @@ groups:
 	if config.Gateway.Timeout != 30*time.Second {
 		t.Errorf("Expected default timeout 30s, got %v", config.Gateway.Timeout)
 	}
+	if config.Gateway.RefreshInterval != 5*time.Minute {
+		t.Errorf("Expected default refresh interval 5m, got %v", config.Gateway.RefreshInterval)
+	}
 
 	if config.Middleware.Logging.Enabled != true {
 		t.Errorf("Expected default logging enabled true, got %v", config.Middleware.Logging.Enabled)
@@ -241,6 +244,372 @@ groups:
         name: "test-backend"
         transport: "stdio"
         command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "group name with slash",
+			config: `
+groups:
+  - name: "team/dev"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "group name with space",
+			config: `
+groups:
+  - name: "design team"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "group name with wildcard",
+			config: `
+groups:
+  - name: "{team}"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "group name with query",
+			config: `
+groups:
+  - name: "team?x"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "group name of dots",
+			config: `
+groups:
+  - name: ".."
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "auth enabled without credentials",
+			config: `
+middleware:
+  auth:
+    enabled: true
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "duplicate API key name",
+			config: `
+middleware:
+  auth:
+    enabled: true
+    api_keys:
+      - name: "ci"
+        key: "key-1"
+      - name: "ci"
+        key: "key-2"
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "API key without key",
+			config: `
+middleware:
+  auth:
+    enabled: true
+    api_keys:
+      - name: "ci"
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "auth with API keys and JWT",
+			config: `
+middleware:
+  auth:
+    enabled: true
+    api_keys:
+      - name: "ci"
+        key: "key-1"
+    jwt:
+      jwks_file: "/etc/mcp-proxy/jwks.json"
+      issuer: "https://auth.example.com"
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: false,
+		},
+		{
+			name: "access policy without auth",
+			config: `
+groups:
+  - name: "test-group"
+    access:
+      roles: ["developer"]
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "access policy claim without values",
+			config: `
+middleware:
+  auth:
+    enabled: true
+    api_keys:
+      - name: "ci"
+        key: "key-1"
+groups:
+  - name: "test-group"
+    access:
+      claims:
+        - claim: "department"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "access policy with auth",
+			config: `
+middleware:
+  auth:
+    enabled: true
+    api_keys:
+      - name: "ci"
+        key: "key-1"
+        roles: ["developer"]
+groups:
+  - name: "test-group"
+    access:
+      users: ["ci"]
+      roles: ["developer"]
+      claims:
+        - claim: "department"
+          values: ["engineering"]
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: false,
+		},
+		{
+			name: "invalid tool pattern",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+        exclude: ["git_[push"]
+`,
+			expectError: true,
+		},
+		{
+			name: "tool include and exclude patterns",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+        include: ["git_*"]
+        exclude: ["git_push"]
+`,
+			expectError: false,
+		},
+		{
+			name: "tool overrides",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+        tools:
+          - tool: "read_file"
+            name: "fs_read"
+            aliases: ["read"]
+            description: "Read a file from the workspace"
+            defaults:
+              - argument: "encoding"
+                value: "utf-8"
+`,
+			expectError: false,
+		},
+		{
+			name: "tool override without tool",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+        tools:
+          - name: "fs_read"
+`,
+			expectError: true,
+		},
+		{
+			name: "tool overrides exposing the same name",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+        tools:
+          - tool: "read_file"
+            aliases: ["read"]
+          - tool: "read_url"
+            name: "read"
+`,
+			expectError: true,
+		},
+		{
+			name: "negative page size",
+			config: `
+gateway:
+  page_size: -1
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "negative backend timeout",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+        timeout: -1s
+`,
+			expectError: true,
+		},
+		{
+			name: "negative tool timeout",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+        tools:
+          - tool: "slow"
+            timeout: -1s
+`,
+			expectError: true,
+		},
+		{
+			name: "unsupported log format",
+			config: `
+middleware:
+  logging:
+    format: "xml"
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "caching without ttl",
+			config: `
+middleware:
+  caching:
+    enabled: true
+    ttl: 0s
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
 `,
 			expectError: true,
 		},
@@ -265,6 +634,123 @@ groups:
       test-backend:
         name: "test-backend"
         transport: "http"
+`,
+			expectError: true,
+		},
+		{
+			name: "valid sse backend",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "sse"
+        endpoint: "http://localhost:3000/sse"
+`,
+			expectError: false,
+		},
+		{
+			name: "valid websocket backend",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "websocket"
+        endpoint: "ws://localhost:3000/mcp"
+`,
+			expectError: false,
+		},
+		{
+			name: "negative restart backoff",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+        restart:
+          backoff: -1s
+`,
+			expectError: true,
+		},
+		{
+			name: "valid health check",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "http"
+        endpoint: "http://localhost:3000/mcp"
+        health_check:
+          interval: 10s
+          timeout: 2s
+          healthy_threshold: 2
+          unhealthy_threshold: 3
+`,
+			expectError: false,
+		},
+		{
+			name: "negative health check threshold",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "http"
+        endpoint: "http://localhost:3000/mcp"
+        health_check:
+          unhealthy_threshold: -1
+`,
+			expectError: true,
+		},
+		{
+			name: "invalid namespace strategy",
+			config: `
+gateway:
+  namespace: "tool"
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: true,
+		},
+		{
+			name: "group namespace override",
+			config: `
+gateway:
+  namespace: "backend"
+groups:
+  - name: "test-group"
+    namespace: "group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "stdio"
+        command: "test-command"
+`,
+			expectError: false,
+		},
+		{
+			name: "missing endpoint for sse",
+			config: `
+groups:
+  - name: "test-group"
+    backends:
+      test-backend:
+        name: "test-backend"
+        transport: "sse"
 `,
 			expectError: true,
 		},
@@ -290,6 +776,146 @@ groups:
 	}
 }
 
+func TestLoadConfigRestartPolicy(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "restart-config.yaml")
+
+	configContent := `
+groups:
+  - name: "restart-group"
+    backends:
+      git-tools:
+        name: "git-tools"
+        transport: "stdio"
+        command: "mcp-server-git"
+        restart:
+          max_restarts: 3
+          backoff: 2s
+          max_backoff: 1m
+`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	restart := config.Groups[0].Backends["git-tools"].Restart
+	if restart.MaxRestarts != 3 {
+		t.Errorf("Expected max restarts 3, got %d", restart.MaxRestarts)
+	}
+	if restart.Backoff != 2*time.Second {
+		t.Errorf("Expected backoff 2s, got %v", restart.Backoff)
+	}
+	if restart.MaxBackoff != time.Minute {
+		t.Errorf("Expected max backoff 1m, got %v", restart.MaxBackoff)
+	}
+}
+
+func TestLoadConfigAuth(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "auth-config.yaml")
+	t.Setenv("TEST_CI_API_KEY", "secret-from-env")
+
+	configContent := `
+middleware:
+  auth:
+    enabled: true
+    api_keys:
+      - name: "ci"
+        key: "${TEST_CI_API_KEY}"
+    jwt:
+      jwks_file: "/etc/mcp-proxy/jwks.json"
+      audience: "mcp-gateway"
+groups:
+  - name: "auth-group"
+    access:
+      claims:
+        - claim: "groupMembership"
+          values: ["Design Team"]
+    backends:
+      git-tools:
+        name: "git-tools"
+        transport: "stdio"
+        command: "mcp-server-git"
+`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	auth := config.Middleware.Auth
+	if !auth.Enabled {
+		t.Error("Expected auth to be enabled")
+	}
+	if len(auth.APIKeys) != 1 || auth.APIKeys[0].Name != "ci" || auth.APIKeys[0].Key != "secret-from-env" {
+		t.Errorf("Expected API key ci with the key from the environment, got %+v", auth.APIKeys)
+	}
+	if auth.JWT.JWKSFile != "/etc/mcp-proxy/jwks.json" || auth.JWT.Audience != "mcp-gateway" {
+		t.Errorf("Unexpected JWT config: %+v", auth.JWT)
+	}
+	if auth.JWT.RolesClaim != "roles" {
+		t.Errorf("Expected default roles claim 'roles', got '%s'", auth.JWT.RolesClaim)
+	}
+
+	access := config.Groups[0].Access
+	if !access.Restricted() || len(access.Claims) != 1 || access.Claims[0].Claim != "groupMembership" || access.Claims[0].Values[0] != "Design Team" {
+		t.Errorf("Unexpected access policy: %+v", access)
+	}
+}
+
+func TestNamespaceFor(t *testing.T) {
+	cfg := &Config{Gateway: GatewayConfig{Namespace: NamespaceBackend}}
+
+	if namespace := cfg.NamespaceFor(Group{Name: "inherits"}); namespace != NamespaceBackend {
+		t.Errorf("Expected group to inherit %s, got %s", NamespaceBackend, namespace)
+	}
+	if namespace := cfg.NamespaceFor(Group{Name: "overrides", Namespace: NamespaceGroup}); namespace != NamespaceGroup {
+		t.Errorf("Expected group override %s, got %s", NamespaceGroup, namespace)
+	}
+
+	empty := &Config{}
+	if namespace := empty.NamespaceFor(Group{Name: "default"}); namespace != NamespaceNone {
+		t.Errorf("Expected default %s, got %s", NamespaceNone, namespace)
+	}
+}
+
+func TestTimeoutFor(t *testing.T) {
+	cfg := &Config{Gateway: GatewayConfig{Timeout: 30 * time.Second}}
+
+	if timeout := cfg.TimeoutFor(Backend{Name: "inherits"}); timeout != 30*time.Second {
+		t.Errorf("Expected backend to inherit 30s, got %s", timeout)
+	}
+	if timeout := cfg.TimeoutFor(Backend{Name: "overrides", Timeout: 2 * time.Minute}); timeout != 2*time.Minute {
+		t.Errorf("Expected backend override 2m0s, got %s", timeout)
+	}
+}
+
+func TestCORSOrigins(t *testing.T) {
+	cfg := &Config{}
+	if origins := cfg.CORSOrigins(); len(origins) != 1 || origins[0] != "*" {
+		t.Errorf("Expected any origin to be allowed by default, got %v", origins)
+	}
+
+	cfg.Middleware.Auth.Enabled = true
+	if origins := cfg.CORSOrigins(); len(origins) != 0 {
+		t.Errorf("Expected only same-origin requests with auth enabled, got %v", origins)
+	}
+
+	cfg.Middleware.CORS.AllowedOrigins = []string{"https://app.example.com"}
+	if origins := cfg.CORSOrigins(); len(origins) != 1 || origins[0] != "https://app.example.com" {
+		t.Errorf("Expected the configured origins, got %v", origins)
+	}
+}
+
 func TestGetConfigPath(t *testing.T) {
 	// テスト用の一時ディレクトリを作成
 	tempDir := t.TempDir()
@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+// StderrProvider is implemented by backends that capture the stderr output
+// of a child process
+type StderrProvider interface {
+	Stderr() []string
+}
+
+// BackendStderrResponse is returned by the backend stderr admin endpoint
+type BackendStderrResponse struct {
+	Backend string   `json:"backend"`
+	Lines   []string `json:"lines"`
+}
+
+// AdminHandler returns the handler for the gateway's administrative
+// endpoints. It serves:
+//
+//	GET /admin/backends/{name}/stderr  recent stderr lines of a stdio backend
+//
+// Stderr often contains credentials, so when authentication is enabled a
+// client may only read the stderr of backends in groups it may access.
+func (g *Gateway) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/backends/{name}/stderr", g.handleBackendStderr)
+	return mux
+}
+
+// handleBackendStderr serves the buffered stderr lines of a backend
+func (g *Gateway) handleBackendStderr(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	backend, exists := g.backendManager.GetBackend(name)
+	if !exists {
+		http.Error(w, fmt.Sprintf("backend %s not found", name), http.StatusNotFound)
+		return
+	}
+
+	if g.authorizer != nil {
+		identity := identityFromTokenInfo(auth.TokenInfoFromContext(r.Context()))
+		if err := g.authorizer.Authorize(identity, backend.GetInfo().Group, fmt.Sprintf("read stderr of backend '%s'", name), ""); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+
+	provider, ok := backend.(StderrProvider)
+	if !ok {
+		http.Error(w, fmt.Sprintf("backend %s does not capture stderr", name), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(BackendStderrResponse{
+		Backend: name,
+		Lines:   provider.Stderr(),
+	})
+}
@@ -0,0 +1,144 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+func TestAdminHandler_BackendStderr(t *testing.T) {
+	cfg := &config.Config{
+		Groups: []config.Group{
+			{
+				Name: "test-group",
+				Backends: map[string]config.Backend{
+					"stdio-backend": helperBackendConfig("stdio-backend"),
+					"http-backend": {
+						Name:      "http-backend",
+						Transport: "http",
+						Endpoint:  "http://localhost:3000/mcp",
+					},
+				},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	backend, _ := gateway.GetBackendManager().GetBackend("stdio-backend")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := backend.Initialize(ctx, testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	params := map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"stderr": "something went wrong"},
+	}
+	if _, err := backend.SendRequest(ctx, "tools/call", params); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	waitFor(t, 5*time.Second, func() bool {
+		return len(backend.(*StdioBackend).Stderr()) > 0
+	})
+
+	handler := gateway.AdminHandler()
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{"stdio backend", "/admin/backends/stdio-backend/stderr", http.StatusOK},
+		{"backend without stderr", "/admin/backends/http-backend/stderr", http.StatusNotFound},
+		{"unknown backend", "/admin/backends/missing/stderr", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, recorder.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response BackendStderrResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if response.Backend != "stdio-backend" || len(response.Lines) != 1 || response.Lines[0] != "something went wrong" {
+				t.Errorf("Unexpected response: %+v", response)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_AuthorizesBackendStderr(t *testing.T) {
+	authConfig := config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "operator", Key: "ops-secret", Roles: []string{"ops"}},
+			{Name: "designer-agent", Key: "designer-secret"},
+		},
+	}
+	cfg := &config.Config{
+		Middleware: config.MiddlewareConfig{Auth: authConfig},
+		Groups: []config.Group{
+			{
+				Name:     "ops",
+				Access:   config.AccessPolicy{Roles: []string{"ops"}},
+				Backends: map[string]config.Backend{"stdio-backend": helperBackendConfig("stdio-backend")},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	authenticator, err := NewAuthenticator(authConfig)
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	handler := authenticator.Middleware(gateway.AdminHandler())
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{"client of the backend's group", "ops-secret", http.StatusOK},
+		{"client denied the backend's group", "designer-secret", http.StatusForbidden},
+		{"unauthenticated client", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/admin/backends/stdio-backend/stderr", nil)
+			if tt.token != "" {
+				request.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
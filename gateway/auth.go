@@ -0,0 +1,151 @@
+package gateway
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// Authentication methods reported in Identity.Method
+const (
+	AuthMethodAPIKey = "api_key"
+	AuthMethodJWT    = "jwt"
+)
+
+// apiKeyLifetime is the expiration reported for API keys. They never expire,
+// but the SDK middleware rejects tokens without an expiration.
+const apiKeyLifetime = time.Hour
+
+// identityExtraKey is the key of the Identity in auth.TokenInfo.Extra
+const identityExtraKey = "identity"
+
+// Identity is an authenticated client of the gateway
+type Identity struct {
+	// Subject names the client: the API key name or the JWT sub claim
+	Subject string
+	// Method is how the client authenticated, AuthMethodAPIKey or AuthMethodJWT
+	Method string
+	// Roles are the roles configured for the API key or found in the JWT
+	Roles []string
+	// Claims holds the claims of a JWT and is nil for API keys
+	Claims map[string]interface{}
+}
+
+// IdentityFromContext returns the identity of the client that made the
+// request, or nil if the request was not authenticated
+func IdentityFromContext(ctx context.Context) *Identity {
+	return identityFromTokenInfo(auth.TokenInfoFromContext(ctx))
+}
+
+// identityFromTokenInfo extracts the identity attached to verified token info
+func identityFromTokenInfo(info *auth.TokenInfo) *Identity {
+	if info == nil {
+		return nil
+	}
+	identity, _ := info.Extra[identityExtraKey].(*Identity)
+	return identity
+}
+
+// Authenticator verifies the bearer tokens presented by clients of the
+// gateway's HTTP endpoints. A token is accepted if it is one of the static
+// API keys or a JWT signed by a key of the configured JWKS file.
+type Authenticator struct {
+	apiKeys    []config.APIKeyConfig
+	jwt        *jwtVerifier
+	rolesClaim string
+}
+
+// NewAuthenticator creates an authenticator from the auth configuration
+func NewAuthenticator(cfg config.AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{apiKeys: cfg.APIKeys, rolesClaim: cfg.JWT.RolesClaim}
+	if a.rolesClaim == "" {
+		a.rolesClaim = "roles"
+	}
+
+	if cfg.JWT.JWKSFile != "" {
+		verifier, err := newJWTVerifier(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = verifier
+	}
+
+	return a, nil
+}
+
+// Middleware rejects requests without a valid bearer token with 401
+// Unauthorized. The identity of authenticated clients is attached to the
+// request context and can be read with IdentityFromContext.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return auth.RequireBearerToken(a.VerifyToken, nil)(next)
+}
+
+// VerifyToken implements auth.TokenVerifier
+func (a *Authenticator) VerifyToken(ctx context.Context, token string, req *http.Request) (*auth.TokenInfo, error) {
+	for _, apiKey := range a.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(apiKey.Key)) == 1 {
+			return &auth.TokenInfo{
+				Expiration: time.Now().Add(apiKeyLifetime),
+				Extra: map[string]any{
+					identityExtraKey: &Identity{Subject: apiKey.Name, Method: AuthMethodAPIKey, Roles: apiKey.Roles},
+				},
+			}, nil
+		}
+	}
+
+	if a.jwt == nil || strings.Count(token, ".") != 2 {
+		return nil, fmt.Errorf("%w: unknown API key", auth.ErrInvalidToken)
+	}
+
+	claims, err := a.jwt.verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrInvalidToken, err)
+	}
+
+	subject, _ := claims["sub"].(string)
+	return &auth.TokenInfo{
+		Scopes:     claimScopes(claims),
+		Expiration: claimTime(claims, "exp"),
+		Extra: map[string]any{
+			identityExtraKey: &Identity{
+				Subject: subject,
+				Method:  AuthMethodJWT,
+				Roles:   claimStrings(claims, a.rolesClaim),
+				Claims:  claims,
+			},
+		},
+	}, nil
+}
+
+// claimScopes returns the OAuth scopes of a JWT, found either in a
+// space-separated "scope" claim or in an "scp" array
+func claimScopes(claims map[string]interface{}) []string {
+	if _, ok := claims["scope"]; ok {
+		return claimStrings(claims, "scope")
+	}
+	return claimStrings(claims, "scp")
+}
+
+// claimStrings returns the values of a claim that is an array of strings or
+// a space-separated string
+func claimStrings(claims map[string]interface{}, name string) []string {
+	switch claim := claims[name].(type) {
+	case string:
+		return strings.Fields(claim)
+	case []interface{}:
+		var values []string
+		for _, item := range claim {
+			if value, ok := item.(string); ok {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+	return nil
+}
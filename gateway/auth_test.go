@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+func TestAuthenticator_Middleware(t *testing.T) {
+	signer := newRSATestSigner(t, "rsa-key")
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksFile, signer)
+
+	authenticator, err := NewAuthenticator(config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{{Name: "ci", Key: "ci-secret"}},
+		JWT:     config.JWTConfig{JWKSFile: jwksFile, Audience: "mcp-gateway"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	var identity *Identity
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity = IdentityFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantSubject   string
+		wantMethod    string
+	}{
+		{"no token", "", http.StatusUnauthorized, "", ""},
+		{"unknown API key", "Bearer wrong-secret", http.StatusUnauthorized, "", ""},
+		{"API key", "Bearer ci-secret", http.StatusOK, "ci", AuthMethodAPIKey},
+		{"JWT", "Bearer " + signer.sign(t, validClaims("alice")), http.StatusOK, "alice", AuthMethodJWT},
+		{"JWT with wrong audience", "Bearer " + signer.sign(t, map[string]interface{}{"sub": "alice", "aud": "other", "exp": 9999999999}), http.StatusUnauthorized, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity = nil
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if identity == nil {
+				t.Fatal("Identity should be attached to the request context")
+			}
+			if identity.Subject != tt.wantSubject || identity.Method != tt.wantMethod {
+				t.Errorf("Expected %s identity %s, got %+v", tt.wantMethod, tt.wantSubject, identity)
+			}
+		})
+	}
+}
+
+func TestAuthenticator_JWTClaims(t *testing.T) {
+	signer := newECTestSigner(t, "ec-key")
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksFile, signer)
+
+	authenticator, err := NewAuthenticator(config.AuthConfig{Enabled: true, JWT: config.JWTConfig{JWKSFile: jwksFile}})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	claims := validClaims("bob")
+	claims["scope"] = "tools:read tools:call"
+	claims["roles"] = []string{"designer"}
+
+	info, err := authenticator.VerifyToken(context.Background(), signer.sign(t, claims), nil)
+	if err != nil {
+		t.Fatalf("VerifyToken failed: %v", err)
+	}
+	if len(info.Scopes) != 2 || info.Scopes[1] != "tools:call" {
+		t.Errorf("Expected scopes from the scope claim, got %v", info.Scopes)
+	}
+
+	identity := identityFromTokenInfo(info)
+	if identity == nil || identity.Subject != "bob" {
+		t.Fatalf("Expected identity bob, got %+v", identity)
+	}
+	if roles, ok := identity.Claims["roles"].([]interface{}); !ok || len(roles) != 1 || roles[0] != "designer" {
+		t.Errorf("Expected the token's claims in the identity, got %v", identity.Claims)
+	}
+}
+
+func TestNewAuthenticator_FailsWithoutJWKSFile(t *testing.T) {
+	_, err := NewAuthenticator(config.AuthConfig{
+		Enabled: true,
+		JWT:     config.JWTConfig{JWKSFile: filepath.Join(t.TempDir(), "missing.json")},
+	})
+	if err == nil {
+		t.Fatal("Expected an error when the JWKS file cannot be read")
+	}
+}
@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// ErrPermissionDenied is returned when a client calls or describes a tool of
+// a group it may not access
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Authorizer decides which groups a client may access, based on the access
+// policies of the configured groups
+type Authorizer struct {
+	policies map[string]config.AccessPolicy // group name -> access policy
+}
+
+// NewAuthorizer creates an authorizer enforcing the groups' access policies
+func NewAuthorizer(groups []config.Group) *Authorizer {
+	a := &Authorizer{policies: make(map[string]config.AccessPolicy)}
+	for _, group := range groups {
+		if group.Access.Restricted() {
+			a.policies[group.Name] = group.Access
+		}
+	}
+	return a
+}
+
+// Allowed reports whether the client may see and call the tools of a group.
+// Groups without an access policy are open to every client. A nil identity,
+// e.g. for a transport that does not pass on the authenticated identity,
+// only has access to those.
+func (a *Authorizer) Allowed(identity *Identity, group string) bool {
+	policy, restricted := a.policies[group]
+	if !restricted {
+		return true
+	}
+	if identity == nil {
+		return false
+	}
+
+	if identity.Subject != "" && slices.Contains(policy.Users, identity.Subject) {
+		return true
+	}
+
+	for _, role := range identity.Roles {
+		if slices.Contains(policy.Roles, role) {
+			return true
+		}
+	}
+
+	for _, match := range policy.Claims {
+		for _, value := range match.Values {
+			if claimContains(identity.Claims, match.Claim, value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Authorize checks that the client may access a group and logs and returns
+// an ErrPermissionDenied error if it may not. what describes the access in
+// both, e.g. "use tool 'git_status'"; sessionID is the client's MCP session,
+// if any.
+func (a *Authorizer) Authorize(identity *Identity, group, what, sessionID string) error {
+	if a.Allowed(identity, group) {
+		return nil
+	}
+
+	client := "anonymous client"
+	if identity != nil {
+		client = fmt.Sprintf("client '%s'", identity.Subject)
+	}
+	slog.Warn("Denied access", "client", client, "access", what, "group", group, "session_id", sessionID)
+	return fmt.Errorf("%w: %s may not %s of group '%s'", ErrPermissionDenied, client, what, group)
+}
@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+func TestAuthorizer_Allowed(t *testing.T) {
+	authorizer := NewAuthorizer([]config.Group{
+		{Name: "open"},
+		{Name: "developer", Access: config.AccessPolicy{Users: []string{"dev-bot"}, Roles: []string{"developer"}}},
+		{Name: "designer", Access: config.AccessPolicy{Claims: []config.ClaimMatch{{Claim: "department", Values: []string{"Product Design"}}}}},
+	})
+
+	devBot := &Identity{Subject: "dev-bot", Method: AuthMethodAPIKey}
+	developer := &Identity{Subject: "alice", Method: AuthMethodJWT, Roles: []string{"developer"}}
+	designer := &Identity{Subject: "bob", Method: AuthMethodJWT, Claims: map[string]interface{}{"department": "Product Design"}}
+	multiDepartment := &Identity{Subject: "carol", Method: AuthMethodJWT, Claims: map[string]interface{}{"department": []interface{}{"Sales", "Product Design"}}}
+
+	tests := []struct {
+		name     string
+		identity *Identity
+		group    string
+		want     bool
+	}{
+		{"anyone may use an open group", devBot, "open", true},
+		{"anonymous client may use an open group", nil, "open", true},
+		{"anonymous client denied", nil, "developer", false},
+		{"listed user", devBot, "developer", true},
+		{"matching role", developer, "developer", true},
+		{"no matching user or role", designer, "developer", false},
+		{"matching claim", designer, "designer", true},
+		{"matching claim in array", multiDepartment, "designer", true},
+		{"claim only partially matching", &Identity{Subject: "dave", Claims: map[string]interface{}{"department": "Design"}}, "designer", false},
+		{"role does not grant claim access", developer, "designer", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := authorizer.Allowed(tt.identity, tt.group); got != tt.want {
+				t.Errorf("Allowed(%+v, %s) = %t, want %t", tt.identity, tt.group, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	authorizer := NewAuthorizer([]config.Group{
+		{Name: "developer", Access: config.AccessPolicy{Users: []string{"dev-bot"}}},
+	})
+
+	if err := authorizer.Authorize(&Identity{Subject: "dev-bot"}, "developer", "use tool 'git_status'", "session-1"); err != nil {
+		t.Errorf("Expected dev-bot to be authorized, got %v", err)
+	}
+
+	err := authorizer.Authorize(&Identity{Subject: "bob"}, "developer", "use tool 'git_status'", "session-1")
+	if !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("Expected permission denied, got %v", err)
+	}
+	if want := "permission denied: client 'bob' may not use tool 'git_status' of group 'developer'"; err.Error() != want {
+		t.Errorf("Expected error %q, got %q", want, err.Error())
+	}
+
+	err = authorizer.Authorize(nil, "developer", "get prompt 'review'", "")
+	if err == nil || !strings.Contains(err.Error(), "anonymous client may not get prompt 'review'") {
+		t.Errorf("Expected an anonymous client to be denied, got %v", err)
+	}
+}
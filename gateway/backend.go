@@ -1,14 +1,20 @@
 package gateway
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os/exec"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -31,6 +37,87 @@ type Backend interface {
 
 	// IsHealthy returns the health status of the backend
 	IsHealthy() bool
+
+	// SetHealthy overrides the health status, e.g. from a health checker
+	SetHealthy(healthy bool)
+
+	// SetMessageHandler sets the handler for notifications and requests
+	// that the backend sends on its own initiative
+	SetMessageHandler(handler MessageHandler)
+}
+
+// TimeoutProvider is implemented by backends that bound requests sent
+// without a deadline by a default timeout
+type TimeoutProvider interface {
+	RequestTimeout() time.Duration
+}
+
+// SelfRestarter is implemented by backends that restart a failed backend
+// server on their own, following their own restart policy
+type SelfRestarter interface {
+	// RestartsItself reports whether restarting the backend server is left
+	// to the backend, which includes having given up on it
+	RestartsItself() bool
+}
+
+// RestartNotifier is implemented by backends that start a new session with
+// the backend server on their own: when a stdio process is restarted, an
+// HTTP session expires or a websocket reconnects. The backend server has
+// forgotten everything about the previous session, such as subscriptions.
+type RestartNotifier interface {
+	// SetRestartHandler sets a function that is called with the result of
+	// the initialize handshake that started the new session
+	SetRestartHandler(handler func(ctx context.Context, result *mcp.InitializeResult))
+}
+
+// restartNotifier holds a restart handler. Backends embed it to implement
+// RestartNotifier.
+type restartNotifier struct {
+	restartMu      sync.RWMutex
+	restartHandler func(ctx context.Context, result *mcp.InitializeResult)
+}
+
+// SetRestartHandler implements RestartNotifier
+func (n *restartNotifier) SetRestartHandler(handler func(ctx context.Context, result *mcp.InitializeResult)) {
+	n.restartMu.Lock()
+	defer n.restartMu.Unlock()
+	n.restartHandler = handler
+}
+
+// notifyRestart calls the restart handler, if any, with the result of the
+// handshake that started a new session
+func (n *restartNotifier) notifyRestart(ctx context.Context, result *mcp.InitializeResult) {
+	n.restartMu.RLock()
+	handler := n.restartHandler
+	n.restartMu.RUnlock()
+
+	if handler != nil {
+		handler(ctx, result)
+	}
+}
+
+// restartHandlerTimeout bounds the restart handler of backends that
+// re-initialize outside a supervised restart
+const restartHandlerTimeout = 30 * time.Second
+
+// defaultRequestTimeout bounds backend requests when no timeout is configured
+const defaultRequestTimeout = 30 * time.Second
+
+// requestTimeout returns the configured request timeout of a backend
+func requestTimeout(cfg config.Backend) time.Duration {
+	if cfg.Timeout > 0 {
+		return cfg.Timeout
+	}
+	return defaultRequestTimeout
+}
+
+// withRequestTimeout bounds ctx by timeout unless the caller already set a
+// deadline, which may be longer for slow tools
+func withRequestTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
 }
 
 // BackendInfo contains metadata about a backend
@@ -40,16 +127,30 @@ type BackendInfo struct {
 	Group     string
 }
 
-// HTTPBackend implements Backend interface for HTTP transport
+// HTTPBackend implements Backend interface for the Streamable HTTP transport
 type HTTPBackend struct {
-	info     BackendInfo
-	config   config.Backend
-	client   *http.Client
-	endpoint string
-	healthy  bool
-	mu       sync.RWMutex
+	messageDispatcher
+	restartNotifier
+	info            BackendInfo
+	config          config.Backend
+	client          *http.Client
+	streamClient    *http.Client
+	endpoint        string
+	healthy         bool
+	mu              sync.RWMutex
+	reqID           int64
+	sessionID       string
+	protocolVersion string
+	initParams      interface{}
+	streamCancel    context.CancelFunc
 }
 
+// errSessionExpired is returned when the backend no longer knows our session
+var errSessionExpired = errors.New("session expired")
+
+// errStreamUnsupported is returned when the backend does not offer a GET stream
+var errStreamUnsupported = errors.New("server does not support a standalone event stream")
+
 // NewHTTPBackend creates a new HTTP backend
 func NewHTTPBackend(cfg config.Backend, groupName string) *HTTPBackend {
 	return &HTTPBackend{
@@ -60,17 +161,33 @@ func NewHTTPBackend(cfg config.Backend, groupName string) *HTTPBackend {
 		},
 		config:   cfg,
 		endpoint: cfg.Endpoint,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		healthy: true,
+		// Requests are bounded by their context, see RequestTimeout
+		client: &http.Client{},
+		// The event stream stays open indefinitely, so it must not time out
+		streamClient: &http.Client{},
+		healthy:      true,
 	}
 }
 
+// RequestTimeout implements TimeoutProvider
+func (b *HTTPBackend) RequestTimeout() time.Duration {
+	return requestTimeout(b.config)
+}
+
 func (b *HTTPBackend) Initialize(ctx context.Context, req interface{}) (*mcp.InitializeResult, error) {
+	ctx, cancel := withRequestTimeout(ctx, b.RequestTimeout())
+	defer cancel()
+
+	// A new initialize always starts a new session
+	b.mu.Lock()
+	b.sessionID = ""
+	b.protocolVersion = ""
+	b.initParams = req
+	b.mu.Unlock()
+
 	response, err := b.sendJSONRPC(ctx, "initialize", req)
 	if err != nil {
-		b.setHealthy(false)
+		b.SetHealthy(false)
 		return nil, err
 	}
 
@@ -79,72 +196,362 @@ func (b *HTTPBackend) Initialize(ctx context.Context, req interface{}) (*mcp.Ini
 		return nil, fmt.Errorf("failed to unmarshal initialize response: %w", err)
 	}
 
-	b.setHealthy(true)
+	if result != nil {
+		b.mu.Lock()
+		b.protocolVersion = result.ProtocolVersion
+		b.mu.Unlock()
+	}
+
+	if err := b.notify(ctx, "notifications/initialized", struct{}{}); err != nil {
+		b.SetHealthy(false)
+		return nil, fmt.Errorf("failed to send initialized notification: %w", err)
+	}
+
+	b.startStream()
+
+	b.SetHealthy(true)
 	return result, nil
 }
 
 func (b *HTTPBackend) SendRequest(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
+	ctx, cancel := withRequestTimeout(ctx, b.RequestTimeout())
+	defer cancel()
+
+	result, err := b.sendJSONRPC(ctx, method, params)
+	if !errors.Is(err, errSessionExpired) {
+		return result, err
+	}
+
+	// The backend dropped our session; start a new one and retry once
+	b.mu.RLock()
+	initParams := b.initParams
+	b.mu.RUnlock()
+
+	slog.Info("Backend session expired, re-initializing", "backend", b.info.Name)
+	initResult, err := b.Initialize(ctx, initParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-initialize expired session: %w", err)
+	}
+
+	// The handler may send requests of its own, so it must not hold up this one
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), restartHandlerTimeout)
+		defer cancel()
+		b.notifyRestart(ctx, initResult)
+	}()
+
 	return b.sendJSONRPC(ctx, method, params)
 }
 
 func (b *HTTPBackend) sendJSONRPC(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
-	request := map[string]interface{}{
+	id := atomic.AddInt64(&b.reqID, 1)
+
+	jsonData, err := json.Marshal(newJSONRPCRequest(id, method, params))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := b.post(ctx, jsonData)
+	if err != nil {
+		// The request may have reached the backend before it was aborted
+		if ctx.Err() != nil {
+			cancelRequest(b.info.Name, b.reply, id, method, ctx.Err())
+			return nil, ctx.Err()
+		}
+		b.SetHealthy(false)
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound && b.getSessionID() != "" && method != "initialize" {
+		b.mu.Lock()
+		b.sessionID = ""
+		b.mu.Unlock()
+		return nil, errSessionExpired
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		b.SetHealthy(false)
+		return nil, fmt.Errorf("HTTP request failed with status %d", resp.StatusCode)
+	}
+
+	if method == "initialize" {
+		if sessionID := resp.Header.Get("Mcp-Session-Id"); sessionID != "" {
+			b.mu.Lock()
+			b.sessionID = sessionID
+			b.mu.Unlock()
+		}
+	}
+
+	var response *jsonRPCMessage
+	if isEventStream(resp) {
+		response, err = b.readEventStreamResponse(ctx, resp.Body, id)
+	} else {
+		response, err = b.readJSONResponse(ctx, resp.Body, id)
+	}
+	if err != nil {
+		// Abandoning the response body aborts the request, but the backend
+		// only stops working on it when told to
+		if ctx.Err() != nil {
+			cancelRequest(b.info.Name, b.reply, id, method, ctx.Err())
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	result, err := response.resultOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	b.SetHealthy(true)
+	return result, nil
+}
+
+// readJSONResponse reads a plain application/json response body
+func (b *HTTPBackend) readJSONResponse(ctx context.Context, body io.Reader, id int64) (*jsonRPCMessage, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	messages, err := decodeJSONRPCMessages(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	// The body may carry notifications or requests besides our response
+	var response *jsonRPCMessage
+	for i := range messages {
+		if messages[i].isResponse() {
+			if messages[i].idKey() == strconv.FormatInt(id, 10) {
+				response = &messages[i]
+			}
+			continue
+		}
+		b.dispatch(ctx, b.info.Name, &messages[i], b.reply)
+	}
+
+	if response == nil {
+		return nil, fmt.Errorf("no result in response")
+	}
+	return response, nil
+}
+
+// readEventStreamResponse reads a text/event-stream response body until the
+// response to our request arrives, dispatching any other messages on the way
+func (b *HTTPBackend) readEventStreamResponse(ctx context.Context, body io.Reader, id int64) (*jsonRPCMessage, error) {
+	events := newSSEReader(body)
+	for {
+		event, err := events.next()
+		if err != nil {
+			if ctx.Err() == nil {
+				b.SetHealthy(false)
+			}
+			return nil, fmt.Errorf("event stream ended before response: %w", err)
+		}
+
+		if event.Event != "" && event.Event != "message" {
+			continue
+		}
+
+		messages, err := decodeJSONRPCMessages([]byte(event.Data))
+		if err != nil {
+			slog.Warn("Invalid message in event stream", "backend", b.info.Name, "error", err)
+			continue
+		}
+
+		for i := range messages {
+			if messages[i].isResponse() {
+				if messages[i].idKey() == strconv.FormatInt(id, 10) {
+					return &messages[i], nil
+				}
+				continue
+			}
+			b.dispatch(ctx, b.info.Name, &messages[i], b.reply)
+		}
+	}
+}
+
+// notify sends a JSON-RPC notification to the backend
+func (b *HTTPBackend) notify(ctx context.Context, method string, params interface{}) error {
+	jsonData, err := json.Marshal(map[string]interface{}{
 		"jsonrpc": "2.0",
-		"id":      1,
 		"method":  method,
 		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
 
-	jsonData, err := json.Marshal(request)
+	resp, err := b.post(ctx, jsonData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return err
 	}
+	_ = resp.Body.Close()
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", b.endpoint, bytes.NewBuffer(jsonData))
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("notification rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// post sends a JSON-RPC payload to the backend endpoint
+func (b *HTTPBackend) post(ctx context.Context, data []byte) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", b.endpoint, bytes.NewBuffer(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	b.setSessionHeaders(httpReq)
+
+	return b.client.Do(httpReq)
+}
 
+// setSessionHeaders adds the configured headers and the session headers
+func (b *HTTPBackend) setSessionHeaders(httpReq *http.Request) {
 	// Set custom headers
 	for key, value := range b.config.Headers {
 		httpReq.Header.Set(key, value)
 	}
 
-	resp, err := b.client.Do(httpReq)
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.sessionID != "" {
+		httpReq.Header.Set("Mcp-Session-Id", b.sessionID)
+	}
+	if b.protocolVersion != "" {
+		httpReq.Header.Set("Mcp-Protocol-Version", b.protocolVersion)
+	}
+}
+
+// reply sends our response to a request initiated by the backend
+func (b *HTTPBackend) reply(data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), b.RequestTimeout())
+	defer cancel()
+
+	resp, err := b.post(ctx, data)
 	if err != nil {
-		b.setHealthy(false)
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return err
 	}
-	defer func() { _ = resp.Body.Close() }()
+	_ = resp.Body.Close()
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		b.setHealthy(false)
-		return nil, fmt.Errorf("HTTP request failed with status %d", resp.StatusCode)
+func (b *HTTPBackend) getSessionID() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.sessionID
+}
+
+// startStream opens the standalone GET event stream on which the backend
+// sends notifications and requests unrelated to any of our requests
+func (b *HTTPBackend) startStream() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b.mu.Lock()
+	if b.streamCancel != nil {
+		b.streamCancel()
 	}
+	b.streamCancel = cancel
+	b.mu.Unlock()
+
+	go b.streamLoop(ctx)
+}
 
-	body, err := io.ReadAll(resp.Body)
+// streamLoop keeps the standalone event stream open, reconnecting with
+// backoff until the backend is closed or turns out not to support it
+func (b *HTTPBackend) streamLoop(ctx context.Context) {
+	backoff := time.Second
+	lastEventID := ""
+
+	for {
+		connected, err := b.listen(ctx, &lastEventID)
+		if errors.Is(err, errStreamUnsupported) || ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			slog.Warn("Event stream error", "backend", b.info.Name, "error", err)
+		}
+		if connected {
+			backoff = time.Second
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > 30*time.Second {
+			backoff = 30 * time.Second
+		}
+	}
+}
+
+// listen reads the standalone event stream until it ends
+func (b *HTTPBackend) listen(ctx context.Context, lastEventID *string) (bool, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", b.endpoint, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return false, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	var jsonRPCResponse map[string]*json.RawMessage
-	if err := json.Unmarshal(body, &jsonRPCResponse); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	httpReq.Header.Set("Accept", "text/event-stream")
+	b.setSessionHeaders(httpReq)
+	if *lastEventID != "" {
+		httpReq.Header.Set("Last-Event-ID", *lastEventID)
 	}
 
-	if errorData, exists := jsonRPCResponse["error"]; exists && errorData != nil {
-		return nil, fmt.Errorf("JSON-RPC error: %s", string(*errorData))
+	resp, err := b.streamClient.Do(httpReq)
+	if err != nil {
+		return false, err
 	}
+	defer func() { _ = resp.Body.Close() }()
 
-	result, exists := jsonRPCResponse["result"]
-	if !exists {
-		return nil, fmt.Errorf("no result in response")
+	if resp.StatusCode == http.StatusMethodNotAllowed {
+		return false, errStreamUnsupported
+	}
+	if resp.StatusCode != http.StatusOK || !isEventStream(resp) {
+		return false, fmt.Errorf("event stream request failed with status %d", resp.StatusCode)
 	}
 
-	b.setHealthy(true)
-	return result, nil
+	events := newSSEReader(resp.Body)
+	for {
+		event, err := events.next()
+		if err != nil {
+			if err == io.EOF {
+				return true, nil
+			}
+			return true, err
+		}
+
+		if event.ID != "" {
+			*lastEventID = event.ID
+		}
+		if event.Event != "" && event.Event != "message" {
+			continue
+		}
+
+		messages, err := decodeJSONRPCMessages([]byte(event.Data))
+		if err != nil {
+			slog.Warn("Invalid message in event stream", "backend", b.info.Name, "error", err)
+			continue
+		}
+		for i := range messages {
+			if !messages[i].isResponse() {
+				b.dispatch(ctx, b.info.Name, &messages[i], b.reply)
+			}
+		}
+	}
+}
+
+// isEventStream reports whether resp carries a text/event-stream body
+func isEventStream(resp *http.Response) bool {
+	return strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream")
 }
 
 func (b *HTTPBackend) GetInfo() BackendInfo {
@@ -152,7 +559,37 @@ func (b *HTTPBackend) GetInfo() BackendInfo {
 }
 
 func (b *HTTPBackend) Close() error {
-	// HTTP clients don't need explicit closing
+	b.mu.Lock()
+	sessionID := b.sessionID
+	b.sessionID = ""
+	if b.streamCancel != nil {
+		b.streamCancel()
+		b.streamCancel = nil
+	}
+	b.mu.Unlock()
+
+	if sessionID == "" {
+		return nil
+	}
+
+	// Explicitly terminate the session on the backend
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, "DELETE", b.endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	for key, value := range b.config.Headers {
+		httpReq.Header.Set(key, value)
+	}
+	httpReq.Header.Set("Mcp-Session-Id", sessionID)
+
+	resp, err := b.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to terminate session: %w", err)
+	}
+	_ = resp.Body.Close()
 	return nil
 }
 
@@ -162,22 +599,59 @@ func (b *HTTPBackend) IsHealthy() bool {
 	return b.healthy
 }
 
-func (b *HTTPBackend) setHealthy(healthy bool) {
+func (b *HTTPBackend) SetHealthy(healthy bool) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.healthy = healthy
 }
 
-// StdioBackend implements Backend interface for stdio transport
+// Restart policy defaults for supervised stdio processes
+const (
+	stdioDefaultMaxRestarts = 5
+	stdioDefaultBackoff     = time.Second
+	stdioDefaultMaxBackoff  = 30 * time.Second
+
+	// stdioStableUptime is how long a process must run before its restart
+	// budget and backoff are reset
+	stdioStableUptime = time.Minute
+
+	// stdioRestartTimeout bounds the initialize handshake after a restart
+	stdioRestartTimeout = 30 * time.Second
+
+	// stdioExitGrace is how long a failed request waits for an exiting
+	// process to be reaped, so that its final stderr output can be reported
+	stdioExitGrace = 200 * time.Millisecond
+
+	// stdioReplyTimeout bounds writing a reply or cancellation to a process
+	// that stopped draining its stdin
+	stdioReplyTimeout = time.Second
+)
+
+// StdioBackend implements Backend interface for stdio transport.
+// Once initialized, the child process is supervised: when it exits on its
+// own it is restarted with exponential backoff and initialized again.
+// Its stderr is logged and the most recent lines are kept for diagnostics.
 type StdioBackend struct {
-	info    BackendInfo
-	config  config.Backend
-	cmd     *exec.Cmd
-	stdin   io.WriteCloser
-	stdout  io.ReadCloser
-	healthy bool
-	mu      sync.RWMutex
-	reqID   int64
+	messageDispatcher
+	restartNotifier
+	info       BackendInfo
+	config     config.Backend
+	cmd        *exec.Cmd
+	stdin      io.WriteCloser
+	stdout     io.ReadCloser
+	healthy    bool
+	mu         sync.RWMutex
+	writing    chan struct{} // holds a token while a write to stdin is in progress
+	pending    *pendingRequests
+	exited     chan struct{}
+	startedAt  time.Time
+	readErr    error
+	stderr     *stderrBuffer
+	initParams interface{}
+	supervised bool
+	gaveUp     bool // the supervisor stopped restarting the process
+	closed     bool
+	closeCh    chan struct{}
 }
 
 // NewStdioBackend creates a new stdio backend
@@ -190,18 +664,49 @@ func NewStdioBackend(cfg config.Backend, groupName string) *StdioBackend {
 		},
 		config:  cfg,
 		healthy: true,
-		reqID:   1,
+		pending: newPendingRequests(),
+		stderr:  newStderrBuffer(stderrBufferLines),
+		writing: make(chan struct{}, 1),
+		closeCh: make(chan struct{}),
 	}
 }
 
 func (b *StdioBackend) Initialize(ctx context.Context, req interface{}) (*mcp.InitializeResult, error) {
+	ctx, cancel := withRequestTimeout(ctx, b.RequestTimeout())
+	defer cancel()
+
+	b.mu.Lock()
+	b.initParams = req
+	b.mu.Unlock()
+
+	result, err := b.initialize(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	startSupervisor := !b.supervised && !b.closed
+	b.supervised = true
+	b.gaveUp = false
+	b.mu.Unlock()
+
+	if startSupervisor {
+		go b.supervise()
+	}
+
+	return result, nil
+}
+
+// initialize starts the process if needed and performs the initialize handshake
+func (b *StdioBackend) initialize(ctx context.Context, req interface{}) (*mcp.InitializeResult, error) {
 	if err := b.start(); err != nil {
+		b.SetHealthy(false)
 		return nil, err
 	}
 
 	response, err := b.sendJSONRPC(ctx, "initialize", req)
 	if err != nil {
-		b.setHealthy(false)
+		b.SetHealthy(false)
 		return nil, err
 	}
 
@@ -210,7 +715,7 @@ func (b *StdioBackend) Initialize(ctx context.Context, req interface{}) (*mcp.In
 		return nil, fmt.Errorf("failed to unmarshal initialize response: %w", err)
 	}
 
-	b.setHealthy(true)
+	b.SetHealthy(true)
 	return result, nil
 }
 
@@ -218,59 +723,295 @@ func (b *StdioBackend) start() error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if b.closed {
+		return fmt.Errorf("backend %s is closed", b.info.Name)
+	}
 	if b.cmd != nil {
 		return nil // Already started
 	}
 
-	b.cmd = exec.Command(b.config.Command, b.config.Args...)
+	cmd := exec.Command(b.config.Command, b.config.Args...)
 
 	// Set environment variables
 	if len(b.config.Env) > 0 {
-		env := b.cmd.Environ()
+		env := cmd.Environ()
 		for key, value := range b.config.Env {
 			env = append(env, fmt.Sprintf("%s=%s", key, value))
 		}
-		b.cmd.Env = env
+		cmd.Env = env
 	}
 
-	stdin, err := b.cmd.StdinPipe()
+	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stdin pipe: %w", err)
 	}
-	b.stdin = stdin
 
-	stdout, err := b.cmd.StdoutPipe()
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
-	b.stdout = stdout
 
-	if err := b.cmd.Start(); err != nil {
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start command: %w", err)
 	}
 
+	b.cmd = cmd
+	b.stdin = stdin
+	b.stdout = stdout
+	b.readErr = nil
+	b.startedAt = time.Now()
+	b.exited = make(chan struct{})
+
+	done := make(chan struct{})
+	stderrDone := make(chan struct{})
+	go b.readLoop(cmd, stdout, done)
+	go b.captureStderr(stderr, stderrDone)
+	go b.wait(cmd, done, stderrDone, b.exited)
+
 	return nil
 }
 
-func (b *StdioBackend) SendRequest(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
-	return b.sendJSONRPC(ctx, method, params)
+// captureStderr logs each line the process writes to stderr and keeps it in
+// the backend's ring buffer. Draining the pipe also keeps a chatty process
+// from blocking on a full stderr.
+func (b *StdioBackend) captureStderr(stderr io.Reader, done chan struct{}) {
+	defer close(done)
+
+	readStderrLines(stderr, func(line string) {
+		b.stderr.add(line)
+		slog.Info("Backend stderr", "backend", b.info.Name, "line", line)
+	})
 }
 
-func (b *StdioBackend) sendJSONRPC(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
+// wait reaps the process once its output has been drained and clears the
+// backend state so that the next start launches a fresh process
+func (b *StdioBackend) wait(cmd *exec.Cmd, done, stderrDone, exited chan struct{}) {
+	defer close(exited)
+
+	// Wait must not be called before all reads from the pipes have completed
+	<-done
+	<-stderrDone
+	err := cmd.Wait()
+
 	b.mu.Lock()
-	currentID := b.reqID
-	b.reqID++
-	b.mu.Unlock()
+	defer b.mu.Unlock()
 
-	request := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      currentID,
-		"method":  method,
-		"params":  params,
+	if b.cmd == cmd {
+		b.cmd = nil
+		b.stdin = nil
+		b.stdout = nil
+		b.healthy = false
+	}
+	if !b.closed {
+		if err == nil {
+			err = errors.New("exit status 0")
+		}
+		slog.Warn("Backend process exited", "backend", b.info.Name, "error", b.withStderr(err))
+	}
+}
+
+// supervise restarts the process whenever it exits until the backend is
+// closed or the restart budget is spent
+func (b *StdioBackend) supervise() {
+	maxRestarts, initialBackoff, maxBackoff := b.restartPolicy()
+	restarts := 0
+	backoff := initialBackoff
+
+	defer func() {
+		b.mu.Lock()
+		b.supervised = false
+		b.gaveUp = !b.closed
+		b.mu.Unlock()
+	}()
+
+	for {
+		b.mu.RLock()
+		exited, startedAt := b.exited, b.startedAt
+		b.mu.RUnlock()
+
+		select {
+		case <-exited:
+		case <-b.closeCh:
+			return
+		}
+
+		if maxRestarts < 0 || b.isClosed() {
+			return
+		}
+
+		// A process that ran for a while earns a fresh restart budget
+		if time.Since(startedAt) >= stdioStableUptime {
+			restarts = 0
+			backoff = initialBackoff
+		}
+
+		for {
+			if restarts >= maxRestarts {
+				slog.Error("Giving up restarting backend process", "backend", b.info.Name, "restarts", restarts)
+				return
+			}
+			restarts++
+
+			select {
+			case <-time.After(backoff):
+			case <-b.closeCh:
+				return
+			}
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+
+			slog.Info("Restarting backend process", "backend", b.info.Name, "attempt", restarts, "max_restarts", maxRestarts)
+			if err := b.restart(); err != nil {
+				slog.Warn("Backend restart failed", "backend", b.info.Name, "error", err)
+				b.stopProcess()
+				continue
+			}
+			break
+		}
+	}
+}
+
+// RestartsItself implements SelfRestarter. Once the process has been
+// initialized, only the supervisor starts it again, so that the restart
+// budget holds.
+func (b *StdioBackend) RestartsItself() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.supervised || b.gaveUp
+}
+
+// restart starts a new process, repeats the initialize handshake and
+// notifies the restart handler
+func (b *StdioBackend) restart() error {
+	b.mu.RLock()
+	initParams := b.initParams
+	b.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), stdioRestartTimeout)
+	defer cancel()
+
+	result, err := b.initialize(ctx, initParams)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Backend process restarted", "backend", b.info.Name)
+	b.notifyRestart(ctx, result)
+	return nil
+}
+
+// restartPolicy returns the configured restart policy with defaults applied
+func (b *StdioBackend) restartPolicy() (maxRestarts int, backoff, maxBackoff time.Duration) {
+	maxRestarts = b.config.Restart.MaxRestarts
+	if maxRestarts == 0 {
+		maxRestarts = stdioDefaultMaxRestarts
+	}
+	backoff = b.config.Restart.Backoff
+	if backoff == 0 {
+		backoff = stdioDefaultBackoff
+	}
+	maxBackoff = b.config.Restart.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = stdioDefaultMaxBackoff
+	}
+	if maxBackoff < backoff {
+		maxBackoff = backoff
+	}
+	return maxRestarts, backoff, maxBackoff
+}
+
+// stopProcess kills the current process and waits until it has been reaped
+func (b *StdioBackend) stopProcess() {
+	b.mu.RLock()
+	cmd, stdin, stdout, exited := b.cmd, b.stdin, b.stdout, b.exited
+	b.mu.RUnlock()
+
+	if cmd == nil {
+		return
+	}
+
+	_ = stdin.Close()
+	_ = stdout.Close()
+	if cmd.Process != nil {
+		_ = cmd.Process.Kill()
+	}
+	<-exited
+}
+
+// readLoop reads the newline-delimited messages on the child's stdout and
+// hands each response to the request waiting for its id. Lines that are not
+// JSON, such as stray prints of the server, are logged and skipped. It runs
+// until stdout fails; the process is then killed so that the supervisor
+// restarts it even if it keeps running.
+func (b *StdioBackend) readLoop(cmd *exec.Cmd, stdout io.Reader, done chan struct{}) {
+	defer close(done)
+
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			b.handleLine(line)
+		}
+		if err != nil {
+			b.mu.Lock()
+			b.readErr = err
+			b.healthy = false
+			b.mu.Unlock()
+			b.pending.failAll()
+			if cmd.Process != nil {
+				_ = cmd.Process.Kill()
+			}
+			return
+		}
+	}
+}
+
+// handleLine delivers a response or dispatches a request or notification
+// read from the child's stdout
+func (b *StdioBackend) handleLine(line []byte) {
+	var msg jsonRPCMessage
+	if err := json.Unmarshal(line, &msg); err != nil {
+		if len(line) > stderrMaxLineLength {
+			line = line[:stderrMaxLineLength]
+		}
+		slog.Warn("Skipping non-JSON line on backend stdout", "backend", b.info.Name, "line", string(line), "error", err)
+		return
 	}
 
-	jsonData, err := json.Marshal(request)
+	if msg.isResponse() {
+		if !b.pending.deliver(&msg) {
+			slog.Warn("Dropping response with unknown id", "backend", b.info.Name, "jsonrpc_id", msg.idKey())
+		}
+		return
+	}
+
+	b.dispatch(context.Background(), b.info.Name, &msg, b.reply)
+}
+
+func (b *StdioBackend) SendRequest(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
+	ctx, cancel := withRequestTimeout(ctx, b.RequestTimeout())
+	defer cancel()
+	return b.sendJSONRPC(ctx, method, params)
+}
+
+// RequestTimeout implements TimeoutProvider
+func (b *StdioBackend) RequestTimeout() time.Duration {
+	return requestTimeout(b.config)
+}
+
+func (b *StdioBackend) sendJSONRPC(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
+	id, responseCh := b.pending.register()
+
+	jsonData, err := json.Marshal(newJSONRPCRequest(id, method, params))
 	if err != nil {
+		b.pending.remove(id)
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
@@ -278,30 +1019,116 @@ func (b *StdioBackend) sendJSONRPC(ctx context.Context, method string, params in
 	jsonData = append(jsonData, '\n')
 
 	// Send request
-	if _, err := b.stdin.Write(jsonData); err != nil {
-		b.setHealthy(false)
-		return nil, fmt.Errorf("failed to write to stdin: %w", err)
+	if err := b.write(ctx, jsonData); err != nil {
+		b.pending.remove(id)
+		b.SetHealthy(false)
+		return nil, err
 	}
 
-	// Read response
-	decoder := json.NewDecoder(b.stdout)
-	var jsonRPCResponse map[string]*json.RawMessage
-	if err := decoder.Decode(&jsonRPCResponse); err != nil {
-		b.setHealthy(false)
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	// Wait for the reader goroutine to deliver the matching response
+	select {
+	case response, ok := <-responseCh:
+		if !ok {
+			// Give a dying process a moment so its last words reach the buffer
+			b.waitExited(stdioExitGrace)
+			return nil, b.withStderr(fmt.Errorf("failed to decode response: %w", b.readError()))
+		}
+		result, err := response.resultOrError()
+		if err != nil {
+			return nil, err
+		}
+		b.SetHealthy(true)
+		return result, nil
+	case <-ctx.Done():
+		b.pending.remove(id)
+		cancelRequest(b.info.Name, b.reply, id, method, ctx.Err())
+		return nil, ctx.Err()
 	}
+}
 
-	if errorData, exists := jsonRPCResponse["error"]; exists && errorData != nil {
-		return nil, fmt.Errorf("JSON-RPC error: %s", string(*errorData))
+// write serializes writes to the child's stdin so that concurrent requests
+// never interleave their bytes. A process that stops draining stdin fills
+// the pipe and blocks the write, so the caller gives up when ctx is done;
+// the write itself is left to complete or fail when the process is stopped.
+func (b *StdioBackend) write(ctx context.Context, data []byte) error {
+	b.mu.RLock()
+	stdin := b.stdin
+	b.mu.RUnlock()
+
+	if stdin == nil {
+		return fmt.Errorf("backend %s is not started", b.info.Name)
 	}
 
-	result, exists := jsonRPCResponse["result"]
-	if !exists {
-		return nil, fmt.Errorf("no result in response")
+	select {
+	case b.writing <- struct{}{}:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to write to stdin: %w", ctx.Err())
 	}
 
-	b.setHealthy(true)
-	return result, nil
+	written := make(chan error, 1)
+	go func() {
+		defer func() { <-b.writing }()
+		_, err := stdin.Write(data)
+		written <- err
+	}()
+
+	select {
+	case err := <-written:
+		if err != nil {
+			return fmt.Errorf("failed to write to stdin: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to write to stdin: %w", ctx.Err())
+	}
+}
+
+// reply sends our response to a request initiated by the backend
+func (b *StdioBackend) reply(data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), stdioReplyTimeout)
+	defer cancel()
+	return b.write(ctx, append(data, '\n'))
+}
+
+// readError returns the error that stopped the reader goroutine
+func (b *StdioBackend) readError() error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.readErr == nil {
+		return io.ErrUnexpectedEOF
+	}
+	return b.readErr
+}
+
+// Stderr returns the most recent lines the process wrote to stderr, oldest first
+func (b *StdioBackend) Stderr() []string {
+	return b.stderr.tail(0)
+}
+
+// waitExited waits up to timeout for the current process to be reaped
+func (b *StdioBackend) waitExited(timeout time.Duration) {
+	b.mu.RLock()
+	exited := b.exited
+	b.mu.RUnlock()
+
+	if exited == nil {
+		return
+	}
+	select {
+	case <-exited:
+	case <-time.After(timeout):
+	}
+}
+
+// withStderr annotates err with the last lines the process wrote to stderr,
+// which usually explain why it failed
+func (b *StdioBackend) withStderr(err error) error {
+	lines := b.stderr.tail(stderrErrorLines)
+	if len(lines) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w (stderr: %s)", err, strings.Join(lines, " | "))
 }
 
 func (b *StdioBackend) GetInfo() BackendInfo {
@@ -310,28 +1137,31 @@ func (b *StdioBackend) GetInfo() BackendInfo {
 
 func (b *StdioBackend) Close() error {
 	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	if b.stdin != nil {
-		_ = b.stdin.Close()
-	}
-	if b.stdout != nil {
-		_ = b.stdout.Close()
-	}
-	if b.cmd != nil && b.cmd.Process != nil {
-		_ = b.cmd.Process.Kill()
-		_ = b.cmd.Wait()
+	if !b.closed {
+		b.closed = true
+		close(b.closeCh)
 	}
+	b.mu.Unlock()
+
+	b.stopProcess()
+	b.pending.failAll()
 	return nil
 }
 
+// isClosed reports whether Close has been called
+func (b *StdioBackend) isClosed() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.closed
+}
+
 func (b *StdioBackend) IsHealthy() bool {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 	return b.healthy
 }
 
-func (b *StdioBackend) setHealthy(healthy bool) {
+func (b *StdioBackend) SetHealthy(healthy bool) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.healthy = healthy
@@ -339,8 +1169,10 @@ func (b *StdioBackend) setHealthy(healthy bool) {
 
 // BackendManager manages multiple backends
 type BackendManager struct {
-	backends map[string]Backend
-	mu       sync.RWMutex
+	backends     map[string]Backend
+	mu           sync.RWMutex
+	healthCancel context.CancelFunc
+	healthWG     sync.WaitGroup
 }
 
 // NewBackendManager creates a new backend manager
@@ -394,15 +1226,59 @@ func (bm *BackendManager) GetHealthyBackends() []Backend {
 	return backends
 }
 
+// StartHealthChecks starts a background health checker for every backend.
+// configs holds per-backend settings keyed by backend name, and onRecover
+// is called whenever a backend that was down passes its checks again.
+func (bm *BackendManager) StartHealthChecks(configs map[string]config.HealthCheckConfig, onRecover RecoveryHandler) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	if bm.healthCancel != nil {
+		return // Already running
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bm.healthCancel = cancel
+
+	for name, backend := range bm.backends {
+		cfg := configs[name]
+		if cfg.Interval < 0 {
+			continue // Disabled for this backend
+		}
+
+		checker := newHealthChecker(backend, cfg, onRecover)
+		bm.healthWG.Add(1)
+		go func() {
+			defer bm.healthWG.Done()
+			checker.run(ctx)
+		}()
+	}
+}
+
+// stopHealthChecks stops the health checkers and waits for them to return
+func (bm *BackendManager) stopHealthChecks() {
+	bm.mu.Lock()
+	cancel := bm.healthCancel
+	bm.healthCancel = nil
+	bm.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		bm.healthWG.Wait()
+	}
+}
+
 // Close closes all backends
 func (bm *BackendManager) Close() error {
+	bm.stopHealthChecks()
+
 	bm.mu.Lock()
 	defer bm.mu.Unlock()
 
 	for _, backend := range bm.backends {
 		if err := backend.Close(); err != nil {
 			// Log error but continue closing others
-			fmt.Printf("Error closing backend %s: %v\n", backend.GetInfo().Name, err)
+			slog.Error("Failed to close backend", "backend", backend.GetInfo().Name, "error", err)
 		}
 	}
 	return nil
@@ -3,8 +3,14 @@ package gateway
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -15,13 +21,26 @@ import (
 // MockHTTPServer creates a test HTTP server for backend testing
 func MockHTTPServer(t *testing.T) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// No standalone event stream or session termination
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
 		var request map[string]interface{}
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			t.Fatalf("Failed to decode request: %v", err)
+			t.Errorf("Failed to decode request: %v", err)
+			return
 		}
 
 		method, _ := request["method"].(string)
 
+		// Notifications are accepted without a response body
+		if _, hasID := request["id"]; !hasID {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
 		response := map[string]interface{}{
 			"jsonrpc": "2.0",
 			"id":      request["id"],
@@ -194,6 +213,222 @@ func TestHTTPBackend_HealthCheck(t *testing.T) {
 	}
 }
 
+// testInitParams returns initialize params accepted by SDK-based servers
+func testInitParams() map[string]interface{} {
+	return map[string]interface{}{
+		"protocolVersion": "2025-03-26",
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]interface{}{
+			"name":    "test-client",
+			"version": "1.0.0",
+		},
+	}
+}
+
+// newStreamableTestServer serves an SDK-based MCP server over Streamable HTTP
+// and records the method and session id of every HTTP request it receives
+func newStreamableTestServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "streamable", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "greet", Description: "Greets"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		Name string `json:"name"`
+	}) (*mcp.CallToolResult, any, error) {
+		if token := req.Params.GetProgressToken(); token != nil {
+			_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{ProgressToken: token, Progress: 1, Total: 2})
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "Hello " + args.Name}}}, nil, nil
+	})
+
+	handler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return server }, nil)
+
+	var mu sync.Mutex
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.Header.Get("Mcp-Session-Id"))
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestHTTPBackend_StreamableSession(t *testing.T) {
+	ts, requests := newStreamableTestServer(t)
+
+	backend := NewHTTPBackend(config.Backend{
+		Name:      "streamable-backend",
+		Transport: "http",
+		Endpoint:  ts.URL,
+	}, "test-group")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := backend.Initialize(ctx, testInitParams())
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if result.ServerInfo == nil || result.ServerInfo.Name != "streamable" {
+		t.Errorf("Unexpected server info: %+v", result.ServerInfo)
+	}
+
+	sessionID := backend.getSessionID()
+	if sessionID == "" {
+		t.Fatal("Expected a session id after initialize")
+	}
+
+	response, err := backend.SendRequest(ctx, "tools/call", map[string]interface{}{
+		"name":      "greet",
+		"arguments": map[string]interface{}{"name": "gateway"},
+	})
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+
+	var toolResult mcp.CallToolResult
+	if err := json.Unmarshal(*response, &toolResult); err != nil {
+		t.Fatalf("Failed to unmarshal tool result: %v", err)
+	}
+	if text, ok := toolResult.Content[0].(*mcp.TextContent); !ok || text.Text != "Hello gateway" {
+		t.Errorf("Unexpected tool result: %v", toolResult.Content)
+	}
+
+	if err := backend.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+
+	var sawDelete bool
+	for i, request := range requests() {
+		if i > 0 && strings.HasPrefix(request, "POST") && request != "POST "+sessionID {
+			t.Errorf("Request %d was sent without the session id: %q", i, request)
+		}
+		if request == "DELETE "+sessionID {
+			sawDelete = true
+		}
+	}
+	if !sawDelete {
+		t.Errorf("Expected DELETE with session id on close, got %v", requests())
+	}
+}
+
+func TestHTTPBackend_EventStreamNotifications(t *testing.T) {
+	ts, _ := newStreamableTestServer(t)
+
+	backend := NewHTTPBackend(config.Backend{
+		Name:      "streamable-backend",
+		Transport: "http",
+		Endpoint:  ts.URL,
+	}, "test-group")
+	defer func() { _ = backend.Close() }()
+
+	handler := &recordingMessageHandler{}
+	backend.SetMessageHandler(handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := backend.Initialize(ctx, testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	_, err := backend.SendRequest(ctx, "tools/call", map[string]interface{}{
+		"name":      "greet",
+		"arguments": map[string]interface{}{"name": "gateway"},
+		"_meta":     map[string]interface{}{"progressToken": "tok"},
+	})
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.notifications) != 1 || !strings.Contains(handler.notifications[0], "notifications/progress") {
+		t.Errorf("Expected progress notification from the event stream, got %v", handler.notifications)
+	}
+}
+
+func TestHTTPBackend_ReinitializesExpiredSession(t *testing.T) {
+	var mu sync.Mutex
+	sessions := 0
+	expired := map[string]bool{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var request map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		method, _ := request["method"].(string)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case method == "initialize":
+			sessions++
+			w.Header().Set("Mcp-Session-Id", fmt.Sprintf("session-%d", sessions))
+		case expired[r.Header.Get("Mcp-Session-Id")]:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case request["id"] == nil:
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      request["id"],
+			"result":  map[string]interface{}{"protocolVersion": "2025-03-26"},
+		})
+	}))
+	defer server.Close()
+
+	backend := NewHTTPBackend(config.Backend{
+		Name:      "expiring-backend",
+		Transport: "http",
+		Endpoint:  server.URL,
+	}, "test-group")
+	restarted := make(chan *mcp.InitializeResult, 1)
+	backend.SetRestartHandler(func(ctx context.Context, result *mcp.InitializeResult) {
+		restarted <- result
+	})
+
+	ctx := context.Background()
+	if _, err := backend.Initialize(ctx, testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	mu.Lock()
+	expired["session-1"] = true
+	mu.Unlock()
+
+	if _, err := backend.SendRequest(ctx, "tools/list", struct{}{}); err != nil {
+		t.Fatalf("SendRequest should transparently re-initialize: %v", err)
+	}
+	if backend.getSessionID() != "session-2" {
+		t.Errorf("Expected new session-2, got %q", backend.getSessionID())
+	}
+
+	select {
+	case result := <-restarted:
+		if result == nil || result.ProtocolVersion != "2025-03-26" {
+			t.Errorf("Expected the restart handler to get the new initialize result, got %+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Restart handler was not called for the new session")
+	}
+}
+
 func TestBackendManager_AddAndGetBackend(t *testing.T) {
 	manager := NewBackendManager()
 
@@ -321,3 +556,408 @@ func TestInitializeResult_MCPCompatibility(t *testing.T) {
 		t.Error("ServerInfo not properly unmarshaled")
 	}
 }
+
+func TestStdioBackend_ConcurrentRequests(t *testing.T) {
+	backend := newHelperStdioBackend(t, "stdio-backend")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := backend.Initialize(ctx, map[string]interface{}{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	const calls = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, calls)
+
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			value := fmt.Sprintf("value-%d", i)
+			params := map[string]interface{}{
+				"name": "echo",
+				"arguments": map[string]interface{}{
+					"value":    value,
+					"delay_ms": (calls - i) % 7 * 5,
+				},
+			}
+
+			response, err := backend.SendRequest(ctx, "tools/call", params)
+			if err != nil {
+				errs <- fmt.Errorf("call %d failed: %w", i, err)
+				return
+			}
+
+			var result mcp.CallToolResult
+			if err := json.Unmarshal(*response, &result); err != nil {
+				errs <- fmt.Errorf("call %d: failed to unmarshal result: %w", i, err)
+				return
+			}
+
+			text, ok := result.Content[0].(*mcp.TextContent)
+			if !ok || text.Text != value {
+				errs <- fmt.Errorf("call %d: expected %q, got %v", i, value, result.Content[0])
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestStdioBackend_ContextCancellation(t *testing.T) {
+	backend := newHelperStdioBackend(t, "stdio-backend")
+
+	if _, err := backend.Initialize(context.Background(), map[string]interface{}{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	params := map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"value": "slow", "delay_ms": 2000},
+	}
+	if _, err := backend.SendRequest(ctx, "tools/call", params); err == nil {
+		t.Fatal("Expected error when context expires before the response arrives")
+	}
+
+	// The backend must still serve later requests
+	if _, err := backend.SendRequest(context.Background(), "ping", struct{}{}); err != nil {
+		t.Errorf("Ping after cancelled request failed: %v", err)
+	}
+}
+
+func TestStdioBackend_SkipsNonJSONOutput(t *testing.T) {
+	backend := newHelperStdioBackend(t, "stdio-backend")
+
+	if _, err := backend.Initialize(context.Background(), map[string]interface{}{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	params := map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"value": "answered", "stdout": "operating normally"},
+	}
+	response, err := backend.SendRequest(context.Background(), "tools/call", params)
+	if err != nil {
+		t.Fatalf("Request after a stray print failed: %v", err)
+	}
+	var result mcp.CallToolResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if text, ok := result.Content[0].(*mcp.TextContent); !ok || text.Text != "answered" {
+		t.Errorf("Expected the response after the stray print, got %v", result.Content)
+	}
+
+	if _, err := backend.SendRequest(context.Background(), "ping", struct{}{}); err != nil {
+		t.Errorf("Ping after a stray print failed: %v", err)
+	}
+	if !backend.IsHealthy() {
+		t.Error("A stray print should not make the backend unhealthy")
+	}
+}
+
+func TestStdioBackend_RequestTimeout(t *testing.T) {
+	cfg := helperBackendConfig("stdio-backend")
+	cfg.Timeout = 50 * time.Millisecond
+	backend := newHelperStdioBackendWithConfig(t, cfg)
+
+	if _, err := backend.Initialize(context.Background(), map[string]interface{}{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	params := map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"value": "slow", "delay_ms": 2000},
+	}
+	if _, err := backend.SendRequest(context.Background(), "tools/call", params); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the request to time out, got %v", err)
+	}
+
+	// The timed out request was cancelled on the backend
+	params = map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"cancelled": true},
+	}
+	response, err := backend.SendRequest(context.Background(), "tools/call", params)
+	if err != nil {
+		t.Fatalf("Request after timeout failed: %v", err)
+	}
+	var result mcp.CallToolResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if text, ok := result.Content[0].(*mcp.TextContent); !ok || text.Text == "" {
+		t.Errorf("Expected the backend to receive notifications/cancelled, got %v", result.Content)
+	}
+}
+
+func TestStdioBackend_InitializeTimeout(t *testing.T) {
+	cfg := helperBackendConfig("stdio-backend")
+	cfg.Env[stdioHelperSilentEnv] = "1"
+	cfg.Timeout = 100 * time.Millisecond
+	backend := newHelperStdioBackendWithConfig(t, cfg)
+
+	start := time.Now()
+	if _, err := backend.Initialize(context.Background(), testInitParams()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the handshake to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Handshake was not abandoned at the timeout, took %v", elapsed)
+	}
+	if backend.IsHealthy() {
+		t.Error("Backend should be unhealthy after the handshake timed out")
+	}
+}
+
+func TestStdioBackend_WriteTimeout(t *testing.T) {
+	cfg := helperBackendConfig("stdio-backend")
+	cfg.Timeout = 200 * time.Millisecond
+	backend := newHelperStdioBackendWithConfig(t, cfg)
+
+	if _, err := backend.Initialize(context.Background(), map[string]interface{}{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	stall := map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"stall_stdin": true},
+	}
+	if _, err := backend.SendRequest(context.Background(), "tools/call", stall); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the stalled request to time out, got %v", err)
+	}
+
+	// Requests larger than the pipe buffer cannot be written to the stalled
+	// process; neither they nor the requests queued behind them block
+	large := map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"value": strings.Repeat("x", 1024*1024)},
+	}
+	for i := 0; i < 2; i++ {
+		start := time.Now()
+		if _, err := backend.SendRequest(context.Background(), "tools/call", large); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected writing to the stalled process to time out, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("Write was not abandoned at the timeout, took %v", elapsed)
+		}
+	}
+	if backend.IsHealthy() {
+		t.Error("Expected a backend whose stdin stalled to be unhealthy")
+	}
+}
+
+func TestHTTPBackend_CancelsAbandonedRequests(t *testing.T) {
+	aborted := make(chan struct{}, 1)
+	cancelled := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage        `json:"id"`
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch request.Method {
+		case "initialize":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      request.ID,
+				"result": map[string]interface{}{
+					"protocolVersion": "2025-06-18",
+					"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+					"serverInfo":      map[string]interface{}{"name": "slow-server", "version": "1.0.0"},
+				},
+			})
+		case "tools/call":
+			// Never answer; the client has to abort the request
+			<-r.Context().Done()
+			aborted <- struct{}{}
+		case "notifications/cancelled":
+			cancelled <- request.Params
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	backend := NewHTTPBackend(config.Backend{
+		Name:      "slow-backend",
+		Transport: "http",
+		Endpoint:  server.URL,
+	}, "test-group")
+	defer func() { _ = backend.Close() }()
+
+	if _, err := backend.Initialize(context.Background(), testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := backend.SendRequest(ctx, "tools/call", map[string]interface{}{"name": "build"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the request to be cancelled, got %v", err)
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the HTTP request to be aborted")
+	}
+	select {
+	case params := <-cancelled:
+		if params["requestId"] == nil || params["reason"] != "request cancelled by client" {
+			t.Errorf("Unexpected notifications/cancelled params: %v", params)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for notifications/cancelled")
+	}
+	if !backend.IsHealthy() {
+		t.Error("A cancelled request must not mark the backend unhealthy")
+	}
+}
+
+func TestStdioBackend_SendRequestBeforeStart(t *testing.T) {
+	backend := NewStdioBackend(config.Backend{
+		Name:      "not-started",
+		Transport: "stdio",
+		Command:   "true",
+	}, "test-group")
+
+	if _, err := backend.SendRequest(context.Background(), "ping", struct{}{}); err == nil {
+		t.Error("Expected error when sending to a backend that was never started")
+	}
+}
+
+// crashHelper makes the helper process behind backend exit
+func crashHelper(t *testing.T, backend *StdioBackend) {
+	t.Helper()
+
+	params := map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"exit": true},
+	}
+	if _, err := backend.SendRequest(context.Background(), "tools/call", params); err == nil {
+		t.Fatal("Expected call to fail when the process exits")
+	}
+}
+
+// waitFor polls condition until it holds or the timeout expires
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) bool {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return true
+}
+
+func TestStdioBackend_RestartsAfterCrash(t *testing.T) {
+	cfg := helperBackendConfig("stdio-backend")
+	cfg.Restart = config.RestartConfig{Backoff: 10 * time.Millisecond}
+	backend := NewStdioBackend(cfg, "test-group")
+	defer func() { _ = backend.Close() }()
+
+	restarted := make(chan *mcp.InitializeResult, 1)
+	backend.SetRestartHandler(func(ctx context.Context, result *mcp.InitializeResult) {
+		restarted <- result
+	})
+
+	if _, err := backend.Initialize(context.Background(), testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	crashHelper(t, backend)
+
+	select {
+	case result := <-restarted:
+		if result.ServerInfo == nil || result.ServerInfo.Name != "stdio-helper" {
+			t.Errorf("Unexpected server info after restart: %+v", result.ServerInfo)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Backend was not restarted")
+	}
+
+	if !backend.IsHealthy() {
+		t.Error("Backend should be healthy after restart")
+	}
+	if _, err := backend.SendRequest(context.Background(), "ping", struct{}{}); err != nil {
+		t.Errorf("Ping after restart failed: %v", err)
+	}
+}
+
+func TestStdioBackend_GivesUpAfterMaxRestarts(t *testing.T) {
+	crashFile := filepath.Join(t.TempDir(), "crash")
+
+	cfg := helperBackendConfig("stdio-backend")
+	cfg.Env[stdioHelperCrashFileEnv] = crashFile
+	cfg.Restart = config.RestartConfig{MaxRestarts: 2, Backoff: 10 * time.Millisecond}
+	backend := NewStdioBackend(cfg, "test-group")
+	defer func() { _ = backend.Close() }()
+
+	if _, err := backend.Initialize(context.Background(), testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	// Every restarted process exits immediately
+	if err := os.WriteFile(crashFile, nil, 0644); err != nil {
+		t.Fatalf("Failed to write crash file: %v", err)
+	}
+	crashHelper(t, backend)
+
+	gaveUp := waitFor(t, 10*time.Second, func() bool {
+		backend.mu.RLock()
+		defer backend.mu.RUnlock()
+		return !backend.supervised
+	})
+	if !gaveUp {
+		t.Fatal("Supervisor did not give up")
+	}
+	if backend.IsHealthy() {
+		t.Error("Backend should stay unhealthy after exhausting its restart budget")
+	}
+}
+
+func TestStdioBackend_NoRestartAfterClose(t *testing.T) {
+	cfg := helperBackendConfig("stdio-backend")
+	cfg.Restart = config.RestartConfig{Backoff: 10 * time.Millisecond}
+	backend := NewStdioBackend(cfg, "test-group")
+
+	restarted := make(chan struct{}, 1)
+	backend.SetRestartHandler(func(ctx context.Context, result *mcp.InitializeResult) {
+		restarted <- struct{}{}
+	})
+
+	if _, err := backend.Initialize(context.Background(), testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := backend.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	select {
+	case <-restarted:
+		t.Error("Closed backend must not be restarted")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// responseCache keeps backend responses for a limited time so that repeated
+// requests do not reach the backend. Entries are dropped per backend when
+// the backend's lists change or it is restarted.
+type responseCache struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	entries   map[cacheKey]cacheEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// cacheKey identifies a cached response
+type cacheKey struct {
+	backend   string
+	method    string
+	tool      string // tool name on the backend for tools/call
+	arguments string // canonical JSON arguments for tools/call
+}
+
+// cacheEntry is a cached response and when it expires
+type cacheEntry struct {
+	value   json.RawMessage
+	expires time.Time
+}
+
+// newResponseCache creates a cache whose entries live for ttl
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		entries: make(map[cacheKey]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+// toolsListKey returns the key of a backend's tool list, all pages combined
+func toolsListKey(backendName string) cacheKey {
+	return cacheKey{backend: backendName, method: "tools/list"}
+}
+
+// toolCallKey returns the key of a tools/call response. Arguments are keyed
+// by their JSON encoding, which sorts object keys and so does not depend on
+// the order the client sent them in.
+func toolCallKey(backendName, toolName string, arguments map[string]interface{}) (cacheKey, error) {
+	data, err := json.Marshal(arguments)
+	if err != nil {
+		return cacheKey{}, fmt.Errorf("failed to canonicalize arguments: %w", err)
+	}
+	return cacheKey{backend: backendName, method: "tools/call", tool: toolName, arguments: string(data)}, nil
+}
+
+// get returns the cached response for key unless it has expired
+func (c *responseCache) get(key cacheKey) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// set caches a response for key
+func (c *responseCache) set(key cacheKey, value json.RawMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.entries[key] = cacheEntry{value: value, expires: now.Add(c.ttl)}
+
+	// Drop entries that expired without being read again
+	if now.Sub(c.lastSweep) >= c.ttl {
+		c.lastSweep = now
+		for key, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+	}
+}
+
+// invalidateBackend drops every cached response of a backend
+func (c *responseCache) invalidateBackend(backendName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if key.backend == backendName {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// handleListChanged is registered with the NotificationRouter to drop a
+// backend's responses as soon as it reports a list change
+func (c *responseCache) handleListChanged(backendName, method string) {
+	c.invalidateBackend(backendName)
+}
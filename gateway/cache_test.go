@@ -0,0 +1,145 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+func TestResponseCache_Expiry(t *testing.T) {
+	now := time.Now()
+	cache := newResponseCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	key := toolsListKey("fs-a")
+	cache.set(key, json.RawMessage(`{"tools":[]}`))
+
+	if value, hit := cache.get(key); !hit || string(value) != `{"tools":[]}` {
+		t.Errorf("Expected a cache hit, got %s (hit=%t)", value, hit)
+	}
+
+	now = now.Add(time.Minute)
+	if _, hit := cache.get(key); hit {
+		t.Error("Expected the entry to expire after the ttl")
+	}
+}
+
+func TestResponseCache_InvalidateBackend(t *testing.T) {
+	cache := newResponseCache(time.Minute)
+	callKey, err := toolCallKey("fs-a", "read", map[string]interface{}{"path": "x"})
+	if err != nil {
+		t.Fatalf("toolCallKey failed: %v", err)
+	}
+	cache.set(toolsListKey("fs-a"), json.RawMessage(`{}`))
+	cache.set(callKey, json.RawMessage(`{}`))
+	cache.set(toolsListKey("fs-b"), json.RawMessage(`{}`))
+
+	cache.invalidateBackend("fs-a")
+
+	if _, hit := cache.get(toolsListKey("fs-a")); hit {
+		t.Error("Expected the tool list of fs-a to be dropped")
+	}
+	if _, hit := cache.get(callKey); hit {
+		t.Error("Expected the tool call result of fs-a to be dropped")
+	}
+	if _, hit := cache.get(toolsListKey("fs-b")); !hit {
+		t.Error("Expected the entries of fs-b to be kept")
+	}
+}
+
+func TestToolCallKey_CanonicalArguments(t *testing.T) {
+	first, _ := toolCallKey("fs-a", "search", map[string]interface{}{"query": "x", "options": map[string]interface{}{"limit": 1, "case": true}})
+	second, _ := toolCallKey("fs-a", "search", map[string]interface{}{"options": map[string]interface{}{"case": true, "limit": 1}, "query": "x"})
+	if first != second {
+		t.Errorf("Expected argument order not to matter: %+v != %+v", first, second)
+	}
+
+	other, _ := toolCallKey("fs-a", "search", map[string]interface{}{"query": "y"})
+	if first == other {
+		t.Error("Expected different arguments to have different keys")
+	}
+}
+
+func TestGateway_CachesIdempotentToolCalls(t *testing.T) {
+	var calls atomic.Int64
+	backendServer := mcp.NewServer(&mcp.Implementation{Name: "counter", Version: "1.0.0"}, nil)
+	for _, name := range []string{"count", "increment"} {
+		mcp.AddTool(backendServer, &mcp.Tool{Name: name}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+			Key string `json:"key"`
+		}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprint(calls.Add(1))}},
+			}, nil, nil
+		})
+	}
+	ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return backendServer }, nil))
+	defer ts.Close()
+
+	cfg := &config.Config{
+		Groups: []config.Group{
+			{
+				Name: "test-group",
+				Backends: map[string]config.Backend{
+					"counter": {
+						Name:      "counter",
+						Transport: "http",
+						Endpoint:  ts.URL,
+						Tools:     []config.ToolOverride{{Tool: "count", Cache: true}},
+					},
+				},
+			},
+		},
+		Middleware: config.MiddlewareConfig{
+			Caching: config.CachingConfig{Enabled: true, TTL: time.Minute},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	ctx := context.Background()
+	if err := gateway.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+
+	session := connectTestClient(t, gateway.GetServer(), nil)
+	call := func(tool, key string) string {
+		t.Helper()
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "call_tool",
+			Arguments: map[string]interface{}{"tool_name": tool, "arguments": map[string]interface{}{"key": key}},
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("call_tool %s failed: %v %v", tool, err, result)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	first := call("count", "a")
+	if again := call("count", "a"); again != first {
+		t.Errorf("Expected the cached result %s, got %s", first, again)
+	}
+	if other := call("count", "b"); other == first {
+		t.Error("Expected different arguments to reach the backend")
+	}
+	if increment := call("increment", "a"); increment == call("increment", "a") {
+		t.Error("Expected tools not marked as cacheable to reach the backend every time")
+	}
+
+	// A list change drops the backend's cached results
+	gateway.cache.handleListChanged("counter", "notifications/tools/list_changed")
+	if refreshed := call("count", "a"); refreshed == first {
+		t.Error("Expected the cached result to be dropped on list_changed")
+	}
+}
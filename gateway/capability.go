@@ -2,12 +2,16 @@ package gateway
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
 )
 
 // GatewayCapabilities represents the aggregated capabilities of all backends
@@ -19,10 +23,80 @@ type GatewayCapabilities struct {
 
 // RoutingTable manages routing information for tools, resources, and prompts
 type RoutingTable struct {
-	ToolsMap     map[string]string // tool name -> backend name
+	ToolsMap     map[string]string // exposed tool name -> backend name
 	ResourcesMap map[string]string // resource URI pattern -> backend name
 	PromptsMap   map[string]string // prompt name -> backend name
 	mu           sync.RWMutex
+
+	// backendTools holds the tools discovered on each backend, from which
+	// ToolsMap and toolRoutes are rebuilt
+	backendTools map[string]backendToolSet
+	// toolRoutes maps exposed and fully qualified tool names to routes
+	toolRoutes map[string]ToolRoute
+	// collisions maps exposed tool names to all backends providing them
+	collisions map[string][]string
+	// backendResources holds the resources discovered on each backend, from
+	// which ResourcesMap and resources are rebuilt
+	backendResources map[string][]*mcp.Resource
+	// resources maps resource URIs to the resources as listed by their backend
+	resources map[string]*mcp.Resource
+	// resourceCollisions maps resource URIs to all backends listing them
+	resourceCollisions map[string][]string
+	// backendTemplates holds the resource templates discovered on each
+	// backend, from which templateRoutes are rebuilt
+	backendTemplates map[string][]*mcp.ResourceTemplate
+	// templateRoutes routes concrete resource URIs by template, most
+	// specific first
+	templateRoutes []templateRoute
+
+	// backendPrompts holds the prompts discovered on each backend by exposed
+	// name, from which PromptsMap, promptRoutes and prompts are rebuilt
+	backendPrompts map[string]map[string]*mcp.Prompt
+	// promptRoutes maps listed prompt names to routes
+	promptRoutes map[string]PromptRoute
+	// prompts maps listed prompt names to the prompts as listed to clients
+	prompts map[string]*mcp.Prompt
+}
+
+// PromptRoute identifies a prompt on a specific backend
+type PromptRoute struct {
+	Backend string // backend name
+	Prompt  string // prompt name on the backend
+}
+
+// ToolRoute identifies a tool on a specific backend
+type ToolRoute struct {
+	Backend string // backend name
+	Tool    string // tool name on the backend
+	// Description replaces the backend's description of the tool if set
+	Description string
+	// Defaults holds argument values used when the client leaves them out
+	Defaults map[string]interface{}
+	// Cache reports whether the tool's results may be cached
+	Cache bool
+	// Timeout overrides the backend's request timeout if set
+	Timeout time.Duration
+}
+
+// backendToolSet is the set of tools discovered on one backend
+type backendToolSet struct {
+	group     string
+	tools     map[string]string              // exposed tool name -> tool name on the backend
+	aliases   map[string]string              // exposed alias -> tool name on the backend
+	overrides map[string]config.ToolOverride // tool name on the backend -> override
+}
+
+// route returns the route to one of the backend's tools
+func (s backendToolSet) route(backendName, toolName string) ToolRoute {
+	override := s.overrides[toolName]
+	route := ToolRoute{Backend: backendName, Tool: toolName, Description: override.Description, Cache: override.Cache, Timeout: override.Timeout}
+	if len(override.Defaults) > 0 {
+		route.Defaults = make(map[string]interface{}, len(override.Defaults))
+		for _, argDefault := range override.Defaults {
+			route.Defaults[argDefault.Argument] = argDefault.Value
+		}
+	}
+	return route
 }
 
 // NewRoutingTable creates a new routing table
@@ -31,13 +105,192 @@ func NewRoutingTable() *RoutingTable {
 		ToolsMap:     make(map[string]string),
 		ResourcesMap: make(map[string]string),
 		PromptsMap:   make(map[string]string),
+		backendTools: make(map[string]backendToolSet),
+		toolRoutes:   make(map[string]ToolRoute),
+		collisions:   make(map[string][]string),
+		resources:    make(map[string]*mcp.Resource),
+
+		backendResources:   make(map[string][]*mcp.Resource),
+		resourceCollisions: make(map[string][]string),
+
+		backendTemplates: make(map[string][]*mcp.ResourceTemplate),
+
+		backendPrompts: make(map[string]map[string]*mcp.Prompt),
+		promptRoutes:   make(map[string]PromptRoute),
+		prompts:        make(map[string]*mcp.Prompt),
+	}
+}
+
+// exposedToolName returns the name a backend tool or prompt is exposed under
+// for a namespacing strategy
+func exposedToolName(namespace string, info BackendInfo, toolName string) string {
+	switch namespace {
+	case config.NamespaceBackend:
+		return info.Name + "." + toolName
+	case config.NamespaceGroup:
+		return info.Group + "/" + info.Name + "/" + toolName
+	default:
+		return toolName
 	}
 }
 
+// setBackendTools replaces the tools routed to a backend and rebuilds the
+// tool routes. overrides, keyed by tool name on the backend, rename tools
+// and add aliases. It reports whether the backend's tools changed.
+func (rt *RoutingTable) setBackendTools(info BackendInfo, namespace string, toolNames []string, overrides map[string]config.ToolOverride) bool {
+	tools := make(map[string]string, len(toolNames))
+	aliases := make(map[string]string)
+	for _, toolName := range toolNames {
+		override := overrides[toolName]
+		override.Tool = toolName
+
+		exposedName := exposedToolName(namespace, info, override.PublicName())
+		if other, taken := tools[exposedName]; taken {
+			slog.Warn("Two tools of a backend are exposed under the same name; keeping the first", "backend", info.Name, "tool", exposedName, "kept", other, "dropped", toolName)
+			continue
+		}
+		tools[exposedName] = toolName
+
+		for _, alias := range override.Aliases {
+			aliases[exposedToolName(namespace, info, alias)] = toolName
+		}
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	previous, exists := rt.backendTools[info.Name]
+	if exists && maps.Equal(previous.tools, tools) && maps.Equal(previous.aliases, aliases) {
+		return false
+	}
+	if !exists && len(tools) == 0 {
+		return false
+	}
+
+	if len(tools) == 0 {
+		delete(rt.backendTools, info.Name)
+	} else {
+		rt.backendTools[info.Name] = backendToolSet{group: info.Group, tools: tools, aliases: aliases, overrides: overrides}
+	}
+	rt.rebuildTools()
+	return true
+}
+
+// rebuildTools recomputes ToolsMap, toolRoutes and collisions. Backends are
+// visited in name order, so when several backends expose the same name the
+// first one wins regardless of discovery order. Aliases only route where no
+// other name does. The caller must hold rt.mu.
+func (rt *RoutingTable) rebuildTools() {
+	previousCollisions := rt.collisions
+
+	rt.ToolsMap = make(map[string]string)
+	rt.toolRoutes = make(map[string]ToolRoute)
+	providers := make(map[string][]string)
+	backendNames := slices.Sorted(maps.Keys(rt.backendTools))
+
+	for _, backendName := range backendNames {
+		toolSet := rt.backendTools[backendName]
+		for exposedName, toolName := range toolSet.tools {
+			route := toolSet.route(backendName, toolName)
+			providers[exposedName] = append(providers[exposedName], backendName)
+
+			if _, taken := rt.ToolsMap[exposedName]; !taken {
+				rt.ToolsMap[exposedName] = backendName
+				rt.toolRoutes[exposedName] = route
+			}
+
+			// Fully qualified names always reach this backend's tool, by
+			// its backend name as well as by its new name if renamed
+			for _, name := range []string{toolName, toolSet.overrides[toolName].Name} {
+				if name != "" {
+					rt.toolRoutes[backendName+"."+name] = route
+					rt.toolRoutes[toolSet.group+"/"+backendName+"/"+name] = route
+				}
+			}
+		}
+	}
+
+	// Exposed names take precedence over qualified names of other tools
+	for exposedName, backendName := range rt.ToolsMap {
+		toolSet := rt.backendTools[backendName]
+		rt.toolRoutes[exposedName] = toolSet.route(backendName, toolSet.tools[exposedName])
+	}
+
+	for _, backendName := range backendNames {
+		toolSet := rt.backendTools[backendName]
+		for alias, toolName := range toolSet.aliases {
+			if _, taken := rt.toolRoutes[alias]; !taken {
+				rt.toolRoutes[alias] = toolSet.route(backendName, toolName)
+			}
+		}
+	}
+
+	rt.collisions = make(map[string][]string)
+	for exposedName, backends := range providers {
+		if len(backends) < 2 {
+			continue
+		}
+		rt.collisions[exposedName] = backends
+		if !slices.Equal(previousCollisions[exposedName], backends) {
+			slog.Warn("Tool name collision; use a qualified name to reach the other backends",
+				"tool", exposedName, "backends", backends, "routed_to", backends[0],
+				"qualified_example", backends[1]+"."+rt.backendTools[backends[1]].tools[exposedName])
+		}
+	}
+}
+
+// ResolveTool returns the backend and backend tool name for an exposed or
+// fully qualified ("backend.tool" or "group/backend/tool") tool name
+func (rt *RoutingTable) ResolveTool(toolName string) (ToolRoute, bool) {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	if route, exists := rt.toolRoutes[toolName]; exists {
+		return route, true
+	}
+
+	// Entries added directly to ToolsMap route under their own name
+	if backendName, exists := rt.ToolsMap[toolName]; exists {
+		return ToolRoute{Backend: backendName, Tool: toolName}, true
+	}
+	return ToolRoute{}, false
+}
+
+// ToolCollisions returns the exposed tool names provided by more than one
+// backend, with the providing backends in routing order
+func (rt *RoutingTable) ToolCollisions() map[string][]string {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	collisions := make(map[string][]string, len(rt.collisions))
+	for name, backends := range rt.collisions {
+		collisions[name] = slices.Clone(backends)
+	}
+	return collisions
+}
+
 // CapabilityDiscoverer handles capability discovery and routing table construction
 type CapabilityDiscoverer struct {
 	backendManager *BackendManager
 	routingTable   *RoutingTable
+	groupTables    map[string]*RoutingTable                  // group name -> routing table of the group's backends
+	namespaces     map[string]string                         // group name -> tool namespacing strategy
+	toolFilters    map[string]toolFilter                     // backend name -> exposed tools
+	toolOverrides  map[string]map[string]config.ToolOverride // backend name -> tool name -> override
+	initResults    map[string]*mcp.InitializeResult          // backend name -> last initialize result
+	mu             sync.Mutex
+}
+
+// DiscoveryChanges reports which lists changed when a backend was refreshed
+type DiscoveryChanges struct {
+	Tools     bool
+	Resources bool
+	Prompts   bool
+}
+
+// Any reports whether any list changed
+func (c DiscoveryChanges) Any() bool {
+	return c.Tools || c.Resources || c.Prompts
 }
 
 // NewCapabilityDiscoverer creates a new capability discoverer
@@ -45,7 +298,102 @@ func NewCapabilityDiscoverer(backendManager *BackendManager) *CapabilityDiscover
 	return &CapabilityDiscoverer{
 		backendManager: backendManager,
 		routingTable:   NewRoutingTable(),
+		groupTables:    make(map[string]*RoutingTable),
+		initResults:    make(map[string]*mcp.InitializeResult),
+		namespaces:     make(map[string]string),
+		toolFilters:    make(map[string]toolFilter),
+		toolOverrides:  make(map[string]map[string]config.ToolOverride),
+	}
+}
+
+// SetNamespace sets the tool namespacing strategy for a group's backends
+func (cd *CapabilityDiscoverer) SetNamespace(groupName, namespace string) {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	cd.namespaces[groupName] = namespace
+}
+
+// namespaceFor returns the tool namespacing strategy of a backend's group
+func (cd *CapabilityDiscoverer) namespaceFor(info BackendInfo) string {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	return cd.namespaces[info.Group]
+}
+
+// SetToolFilter restricts the tools of a backend that enter the routing
+// table to those matching an include pattern, if any, and no exclude pattern
+func (cd *CapabilityDiscoverer) SetToolFilter(backendName string, include, exclude []string) {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	cd.toolFilters[backendName] = toolFilter{include: include, exclude: exclude}
+}
+
+// toolFilterFor returns the tool filter of a backend
+func (cd *CapabilityDiscoverer) toolFilterFor(info BackendInfo) toolFilter {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	return cd.toolFilters[info.Name]
+}
+
+// SetToolOverrides sets how a backend's tools are renamed and described
+func (cd *CapabilityDiscoverer) SetToolOverrides(backendName string, overrides []config.ToolOverride) {
+	byTool := make(map[string]config.ToolOverride, len(overrides))
+	for _, override := range overrides {
+		byTool[override.Tool] = override
 	}
+
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	cd.toolOverrides[backendName] = byTool
+}
+
+// toolOverridesFor returns the tool overrides of a backend
+func (cd *CapabilityDiscoverer) toolOverridesFor(info BackendInfo) map[string]config.ToolOverride {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	return cd.toolOverrides[info.Name]
+}
+
+// GetGroupRoutingTable returns the routing table holding only the entries of
+// a group's backends, creating it if needed
+func (cd *CapabilityDiscoverer) GetGroupRoutingTable(groupName string) *RoutingTable {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	rt, exists := cd.groupTables[groupName]
+	if !exists {
+		rt = NewRoutingTable()
+		cd.groupTables[groupName] = rt
+	}
+	return rt
+}
+
+// routingTablesFor returns the routing tables a backend's entries belong to:
+// the gateway-wide table and the table of the backend's group
+func (cd *CapabilityDiscoverer) routingTablesFor(info BackendInfo) []*RoutingTable {
+	return []*RoutingTable{cd.routingTable, cd.GetGroupRoutingTable(info.Group)}
+}
+
+// GroupCapabilities returns the combined capabilities of the initialized
+// backends of a group
+func (cd *CapabilityDiscoverer) GroupCapabilities(groupName string) GatewayCapabilities {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	capabilities := GatewayCapabilities{}
+	for _, backend := range cd.backendManager.GetAllBackends() {
+		backendInfo := backend.GetInfo()
+		initResp := cd.initResults[backendInfo.Name]
+		if backendInfo.Group != groupName || initResp == nil || initResp.Capabilities == nil {
+			continue
+		}
+		capabilities = capabilities.merge(GatewayCapabilities{
+			Tools:     initResp.Capabilities.Tools != nil,
+			Resources: initResp.Capabilities.Resources != nil,
+			Prompts:   initResp.Capabilities.Prompts != nil,
+		})
+	}
+	return capabilities
 }
 
 // DiscoverCapabilities performs capability discovery on all backends
@@ -55,141 +403,331 @@ func (cd *CapabilityDiscoverer) DiscoverCapabilities(ctx context.Context) (Gatew
 
 	for _, backend := range backends {
 		backendInfo := backend.GetInfo()
-		log.Printf("Discovering capabilities for backend: %s", backendInfo.Name)
-
-		// Initialize backend - simplified call
-		initReq := struct {
-			ProtocolVersion string                 `json:"protocolVersion"`
-			Capabilities    map[string]interface{} `json:"capabilities"`
-			ClientInfo      struct {
-				Name    string `json:"name"`
-				Version string `json:"version"`
-			} `json:"clientInfo"`
-		}{
-			ProtocolVersion: "2024-11-05",
-			Capabilities:    map[string]interface{}{},
-			ClientInfo: struct {
-				Name    string `json:"name"`
-				Version string `json:"version"`
-			}{
-				Name:    "mcp-gateway",
-				Version: "1.0.0",
-			},
-		}
+		slog.Info("Discovering backend capabilities", "backend", backendInfo.Name, "group", backendInfo.Group)
 
-		initResp, err := backend.Initialize(ctx, initReq)
+		initResp, err := backend.Initialize(ctx, gatewayInitializeParams())
 		if err != nil {
-			log.Printf("Backend %s initialization failed: %v", backendInfo.Name, err)
+			slog.Error("Backend initialization failed", "backend", backendInfo.Name, "group", backendInfo.Group, "error", err)
 			continue
 		}
 
-		// Check and aggregate capabilities
-		if initResp.Capabilities != nil {
-			if initResp.Capabilities.Tools != nil {
-				capabilities.Tools = true
-				if err := cd.discoverTools(ctx, backend); err != nil {
-					log.Printf("Failed to discover tools for backend %s: %v", backendInfo.Name, err)
-				}
-			}
-
-			if initResp.Capabilities.Resources != nil {
-				capabilities.Resources = true
-				if err := cd.discoverResources(ctx, backend); err != nil {
-					log.Printf("Failed to discover resources for backend %s: %v", backendInfo.Name, err)
-				}
-			}
-
-			if initResp.Capabilities.Prompts != nil {
-				capabilities.Prompts = true
-				if err := cd.discoverPrompts(ctx, backend); err != nil {
-					log.Printf("Failed to discover prompts for backend %s: %v", backendInfo.Name, err)
-				}
-			}
-		}
+		backendCapabilities, _ := cd.RefreshBackend(ctx, backend, initResp)
+		capabilities = capabilities.merge(backendCapabilities)
 	}
 
 	return capabilities, nil
 }
 
-// discoverTools discovers and maps tools from a backend
-func (cd *CapabilityDiscoverer) discoverTools(ctx context.Context, backend Backend) error {
-	response, err := backend.SendRequest(ctx, "tools/list", struct{}{})
-	if err != nil {
-		return fmt.Errorf("failed to list tools: %w", err)
+// RefreshBackend discovers the tools, resources and prompts of an already
+// initialized backend and replaces its entries in the routing table. When
+// initResp is nil, the result of the backend's last initialization is used.
+func (cd *CapabilityDiscoverer) RefreshBackend(ctx context.Context, backend Backend, initResp *mcp.InitializeResult) (GatewayCapabilities, DiscoveryChanges) {
+	capabilities := GatewayCapabilities{}
+	changes := DiscoveryChanges{}
+	backendInfo := backend.GetInfo()
+
+	cd.mu.Lock()
+	if initResp != nil {
+		cd.initResults[backendInfo.Name] = initResp
+	} else {
+		initResp = cd.initResults[backendInfo.Name]
 	}
+	cd.mu.Unlock()
 
-	var toolsResponse struct {
-		Tools []mcp.Tool `json:"tools"`
+	if initResp == nil {
+		return capabilities, changes // Never initialized
 	}
 
-	if err := json.Unmarshal(*response, &toolsResponse); err != nil {
-		return fmt.Errorf("failed to unmarshal tools response: %w", err)
+	serverCapabilities := initResp.Capabilities
+	if serverCapabilities == nil {
+		serverCapabilities = &mcp.ServerCapabilities{}
 	}
 
-	backendInfo := backend.GetInfo()
-	cd.routingTable.mu.Lock()
-	defer cd.routingTable.mu.Unlock()
+	// Check and aggregate capabilities. A capability the backend no longer
+	// advertises drops all of its entries.
+	var err error
+	capabilities.Tools = serverCapabilities.Tools != nil
+	if changes.Tools, err = cd.discoverTools(ctx, backend, capabilities.Tools); err != nil {
+		slog.Warn("Failed to discover tools", "backend", backendInfo.Name, "error", err)
+	}
 
-	for _, tool := range toolsResponse.Tools {
-		cd.routingTable.ToolsMap[tool.Name] = backendInfo.Name
-		log.Printf("Mapped tool %s to backend %s", tool.Name, backendInfo.Name)
+	capabilities.Resources = serverCapabilities.Resources != nil
+	if changes.Resources, err = cd.discoverResources(ctx, backend, capabilities.Resources); err != nil {
+		slog.Warn("Failed to discover resources", "backend", backendInfo.Name, "error", err)
+	}
+	templatesChanged, err := cd.discoverResourceTemplates(ctx, backend, capabilities.Resources)
+	if err != nil {
+		slog.Warn("Failed to discover resource templates", "backend", backendInfo.Name, "error", err)
 	}
+	changes.Resources = changes.Resources || templatesChanged
 
-	return nil
+	capabilities.Prompts = serverCapabilities.Prompts != nil
+	if changes.Prompts, err = cd.discoverPrompts(ctx, backend, capabilities.Prompts); err != nil {
+		slog.Warn("Failed to discover prompts", "backend", backendInfo.Name, "error", err)
+	}
+
+	return capabilities, changes
 }
 
-// discoverResources discovers and maps resources from a backend
-func (cd *CapabilityDiscoverer) discoverResources(ctx context.Context, backend Backend) error {
-	response, err := backend.SendRequest(ctx, "resources/list", struct{}{})
-	if err != nil {
-		return fmt.Errorf("failed to list resources: %w", err)
+// merge returns the union of two capability sets
+func (c GatewayCapabilities) merge(other GatewayCapabilities) GatewayCapabilities {
+	return GatewayCapabilities{
+		Tools:     c.Tools || other.Tools,
+		Resources: c.Resources || other.Resources,
+		Prompts:   c.Prompts || other.Prompts,
+	}
+}
+
+// gatewayInitializeParams builds the initialize request the gateway sends to backends
+func gatewayInitializeParams() interface{} {
+	return struct {
+		ProtocolVersion string                 `json:"protocolVersion"`
+		Capabilities    map[string]interface{} `json:"capabilities"`
+		ClientInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"clientInfo"`
+	}{
+		ProtocolVersion: "2024-11-05",
+		Capabilities:    map[string]interface{}{},
+		ClientInfo: struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}{
+			Name:    "mcp-gateway",
+			Version: "1.0.0",
+		},
+	}
+}
+
+// discoverTools discovers and maps tools from a backend. It reports whether
+// the set of tools routed to the backend changed.
+func (cd *CapabilityDiscoverer) discoverTools(ctx context.Context, backend Backend, supported bool) (bool, error) {
+	backendInfo := backend.GetInfo()
+	filter := cd.toolFilterFor(backendInfo)
+
+	var names, hidden []string
+	if supported {
+		tools, err := listAll[mcp.Tool](ctx, backend, "tools/list", "tools")
+		if err != nil {
+			return false, fmt.Errorf("failed to list tools: %w", err)
+		}
+
+		for _, tool := range tools {
+			if filter.allows(tool.Name) {
+				names = append(names, tool.Name)
+			} else {
+				hidden = append(hidden, tool.Name)
+			}
+		}
 	}
 
-	var resourcesResponse struct {
-		Resources []mcp.Resource `json:"resources"`
+	namespace := cd.namespaceFor(backendInfo)
+	overrides := cd.toolOverridesFor(backendInfo)
+	changed := false
+	for _, rt := range cd.routingTablesFor(backendInfo) {
+		if rt.setBackendTools(backendInfo, namespace, names, overrides) {
+			changed = true
+		}
 	}
+	if changed {
+		slog.Info("Mapped tools", "backend", backendInfo.Name, "tools", names)
+		if len(hidden) > 0 {
+			slog.Info("Hiding filtered tools", "backend", backendInfo.Name, "tools", hidden)
+		}
+	}
+	return changed, nil
+}
 
-	if err := json.Unmarshal(*response, &resourcesResponse); err != nil {
-		return fmt.Errorf("failed to unmarshal resources response: %w", err)
+// discoverResources discovers and maps resources from a backend. It reports
+// whether the set of resources routed to the backend changed.
+func (cd *CapabilityDiscoverer) discoverResources(ctx context.Context, backend Backend, supported bool) (bool, error) {
+	var resources []*mcp.Resource
+	if supported {
+		listed, err := listAll[*mcp.Resource](ctx, backend, "resources/list", "resources")
+		if err != nil {
+			return false, fmt.Errorf("failed to list resources: %w", err)
+		}
+		resources = listed
 	}
 
 	backendInfo := backend.GetInfo()
-	cd.routingTable.mu.Lock()
-	defer cd.routingTable.mu.Unlock()
+	changed := false
+	for _, rt := range cd.routingTablesFor(backendInfo) {
+		if rt.setBackendResources(backendInfo.Name, resources) {
+			changed = true
+		}
+	}
+	if changed {
+		uris := make([]string, 0, len(resources))
+		for _, resource := range resources {
+			uris = append(uris, resource.URI)
+		}
+		slog.Info("Mapped resources", "backend", backendInfo.Name, "resources", uris)
+	}
+	return changed, nil
+}
+
+// setBackendResources replaces the resources routed to a backend and
+// rebuilds the resource routes. It reports whether the resources listed to
+// clients or the backends they route to changed.
+func (rt *RoutingTable) setBackendResources(backendName string, resources []*mcp.Resource) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	previous, exists := rt.backendResources[backendName]
+	if exists && reflect.DeepEqual(previous, resources) {
+		return false
+	}
+	if !exists && len(resources) == 0 {
+		return false
+	}
 
-	for _, resource := range resourcesResponse.Resources {
-		cd.routingTable.ResourcesMap[resource.URI] = backendInfo.Name
-		log.Printf("Mapped resource %s to backend %s", resource.URI, backendInfo.Name)
+	if len(resources) == 0 {
+		delete(rt.backendResources, backendName)
+	} else {
+		rt.backendResources[backendName] = resources
 	}
 
-	return nil
+	previousRoutes, previousResources := rt.ResourcesMap, rt.resources
+	rt.rebuildResources()
+	return !maps.Equal(previousRoutes, rt.ResourcesMap) || !reflect.DeepEqual(previousResources, rt.resources)
 }
 
-// discoverPrompts discovers and maps prompts from a backend
-func (cd *CapabilityDiscoverer) discoverPrompts(ctx context.Context, backend Backend) error {
-	response, err := backend.SendRequest(ctx, "prompts/list", struct{}{})
-	if err != nil {
-		return fmt.Errorf("failed to list prompts: %w", err)
+// rebuildResources recomputes ResourcesMap and resources. As with tools,
+// backends are visited in name order, so when several backends list the
+// same URI the first one keeps it regardless of discovery order. The caller
+// must hold rt.mu.
+func (rt *RoutingTable) rebuildResources() {
+	previousCollisions := rt.resourceCollisions
+
+	rt.ResourcesMap = make(map[string]string)
+	rt.resources = make(map[string]*mcp.Resource)
+	providers := make(map[string][]string)
+
+	for _, backendName := range slices.Sorted(maps.Keys(rt.backendResources)) {
+		for _, resource := range rt.backendResources[backendName] {
+			if slices.Contains(providers[resource.URI], backendName) {
+				continue
+			}
+			providers[resource.URI] = append(providers[resource.URI], backendName)
+
+			if _, taken := rt.ResourcesMap[resource.URI]; !taken {
+				rt.ResourcesMap[resource.URI] = backendName
+				rt.resources[resource.URI] = resource
+			}
+		}
 	}
 
-	var promptsResponse struct {
-		Prompts []mcp.Prompt `json:"prompts"`
+	rt.resourceCollisions = make(map[string][]string)
+	for uri, backends := range providers {
+		if len(backends) < 2 {
+			continue
+		}
+		rt.resourceCollisions[uri] = backends
+		if !slices.Equal(previousCollisions[uri], backends) {
+			slog.Warn("Resource URI collision; reads are routed to the first backend", "uri", uri, "backends", backends, "routed_to", backends[0])
+		}
 	}
+}
 
-	if err := json.Unmarshal(*response, &promptsResponse); err != nil {
-		return fmt.Errorf("failed to unmarshal prompts response: %w", err)
+// discoverPrompts discovers and maps prompts from a backend. It reports
+// whether the set of prompts routed to the backend changed.
+func (cd *CapabilityDiscoverer) discoverPrompts(ctx context.Context, backend Backend, supported bool) (bool, error) {
+	var prompts []*mcp.Prompt
+	if supported {
+		listed, err := listAll[*mcp.Prompt](ctx, backend, "prompts/list", "prompts")
+		if err != nil {
+			return false, fmt.Errorf("failed to list prompts: %w", err)
+		}
+		prompts = listed
 	}
 
 	backendInfo := backend.GetInfo()
-	cd.routingTable.mu.Lock()
-	defer cd.routingTable.mu.Unlock()
+	namespace := cd.namespaceFor(backendInfo)
+	changed := false
+	for _, rt := range cd.routingTablesFor(backendInfo) {
+		if rt.setBackendPrompts(backendInfo, namespace, prompts) {
+			changed = true
+		}
+	}
+	if changed {
+		names := make([]string, 0, len(prompts))
+		for _, prompt := range prompts {
+			names = append(names, prompt.Name)
+		}
+		slog.Info("Mapped prompts", "backend", backendInfo.Name, "prompts", names)
+	}
+	return changed, nil
+}
 
-	for _, prompt := range promptsResponse.Prompts {
-		cd.routingTable.PromptsMap[prompt.Name] = backendInfo.Name
-		log.Printf("Mapped prompt %s to backend %s", prompt.Name, backendInfo.Name)
+// setBackendPrompts replaces the prompts routed to a backend and rebuilds
+// the prompt routes. Prompts are named by the namespacing strategy like
+// tools. It reports whether the backend's prompts changed.
+func (rt *RoutingTable) setBackendPrompts(info BackendInfo, namespace string, prompts []*mcp.Prompt) bool {
+	exposed := make(map[string]*mcp.Prompt, len(prompts))
+	for _, prompt := range prompts {
+		exposedName := exposedToolName(namespace, info, prompt.Name)
+		if _, taken := exposed[exposedName]; taken {
+			slog.Warn("Two prompts of a backend have the same name; keeping the first", "backend", info.Name, "prompt", exposedName)
+			continue
+		}
+		exposed[exposedName] = prompt
 	}
 
-	return nil
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	previous, exists := rt.backendPrompts[info.Name]
+	if exists && reflect.DeepEqual(previous, exposed) {
+		return false
+	}
+	if !exists && len(exposed) == 0 {
+		return false
+	}
+
+	if len(exposed) == 0 {
+		delete(rt.backendPrompts, info.Name)
+	} else {
+		rt.backendPrompts[info.Name] = exposed
+	}
+	rt.rebuildPrompts()
+	return true
+}
+
+// rebuildPrompts recomputes PromptsMap, promptRoutes and prompts. As with
+// tools, backends are visited in name order and the first one to expose a
+// name keeps it. Since clients can only get listed prompts, the prompts of
+// the other backends are listed under their qualified name ("backend.prompt")
+// instead. The caller must hold rt.mu.
+func (rt *RoutingTable) rebuildPrompts() {
+	rt.PromptsMap = make(map[string]string)
+	rt.promptRoutes = make(map[string]PromptRoute)
+	rt.prompts = make(map[string]*mcp.Prompt)
+
+	for _, backendName := range slices.Sorted(maps.Keys(rt.backendPrompts)) {
+		prompts := rt.backendPrompts[backendName]
+		for _, exposedName := range slices.Sorted(maps.Keys(prompts)) {
+			prompt := prompts[exposedName]
+
+			name := exposedName
+			if other, taken := rt.PromptsMap[name]; taken {
+				name = backendName + "." + prompt.Name
+				if _, taken := rt.PromptsMap[name]; taken {
+					slog.Warn("Prompt name collision; dropping the prompt", "prompt", exposedName, "backend", backendName, "routed_to", other)
+					continue
+				}
+				slog.Warn("Prompt name collision; listing the prompt under its qualified name",
+					"prompt", exposedName, "backend", backendName, "routed_to", other, "qualified_name", name)
+			}
+
+			listed := *prompt
+			listed.Name = name
+			rt.PromptsMap[name] = backendName
+			rt.promptRoutes[name] = PromptRoute{Backend: backendName, Prompt: prompt.Name}
+			rt.prompts[name] = &listed
+		}
+	}
 }
 
 // GetRoutingTable returns the current routing table
@@ -206,7 +744,9 @@ func (rt *RoutingTable) FindToolBackend(toolName string) (string, bool) {
 	return backendName, exists
 }
 
-// FindResourceBackend finds the backend that provides a specific resource
+// FindResourceBackend finds the backend that provides a specific resource:
+// the backend listing the URI, or else the backend whose most specific
+// resource template matches it
 func (rt *RoutingTable) FindResourceBackend(resourceURI string) (string, bool) {
 	rt.mu.RLock()
 	defer rt.mu.RUnlock()
@@ -216,9 +756,7 @@ func (rt *RoutingTable) FindResourceBackend(resourceURI string) (string, bool) {
 		return backendName, true
 	}
 
-	// Pattern matching could be implemented here for more complex URI matching
-	// For now, we use exact matching
-	return "", false
+	return rt.matchTemplate(resourceURI)
 }
 
 // FindPromptBackend finds the backend that provides a specific prompt
@@ -230,16 +768,46 @@ func (rt *RoutingTable) FindPromptBackend(promptName string) (string, bool) {
 	return backendName, exists
 }
 
+// ResolvePrompt returns the backend and backend prompt name for a listed
+// prompt name
+func (rt *RoutingTable) ResolvePrompt(promptName string) (PromptRoute, bool) {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	if route, exists := rt.promptRoutes[promptName]; exists {
+		return route, true
+	}
+
+	// Entries added directly to PromptsMap route under their own name
+	if backendName, exists := rt.PromptsMap[promptName]; exists {
+		return PromptRoute{Backend: backendName, Prompt: promptName}, true
+	}
+	return PromptRoute{}, false
+}
+
+// GetPrompts returns the prompts of all backends under their listed names,
+// ordered by name
+func (rt *RoutingTable) GetPrompts() []*mcp.Prompt {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	prompts := make([]*mcp.Prompt, 0, len(rt.PromptsMap))
+	for _, name := range slices.Sorted(maps.Keys(rt.PromptsMap)) {
+		prompt := mcp.Prompt{Name: name}
+		if listed, exists := rt.prompts[name]; exists {
+			prompt = *listed
+		}
+		prompts = append(prompts, &prompt)
+	}
+	return prompts
+}
+
 // GetAllTools returns all available tools from all backends
 func (rt *RoutingTable) GetAllTools() []string {
 	rt.mu.RLock()
 	defer rt.mu.RUnlock()
 
-	tools := make([]string, 0, len(rt.ToolsMap))
-	for tool := range rt.ToolsMap {
-		tools = append(tools, tool)
-	}
-	return tools
+	return slices.Sorted(maps.Keys(rt.ToolsMap))
 }
 
 // GetAllResources returns all available resources from all backends
@@ -254,6 +822,23 @@ func (rt *RoutingTable) GetAllResources() []string {
 	return resources
 }
 
+// GetResources returns the resources listed by all backends, ordered by URI.
+// Resources routed without a listing are described by their URI alone.
+func (rt *RoutingTable) GetResources() []*mcp.Resource {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	resources := make([]*mcp.Resource, 0, len(rt.ResourcesMap))
+	for _, uri := range slices.Sorted(maps.Keys(rt.ResourcesMap)) {
+		resource := mcp.Resource{URI: uri, Name: uri}
+		if listed, exists := rt.resources[uri]; exists {
+			resource = *listed
+		}
+		resources = append(resources, &resource)
+	}
+	return resources
+}
+
 // GetAllPrompts returns all available prompts from all backends
 func (rt *RoutingTable) GetAllPrompts() []string {
 	rt.mu.RLock()
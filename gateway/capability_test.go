@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"testing"
+
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
 )
 
 // MockBackend implements Backend interface for testing
@@ -271,3 +274,129 @@ func TestRoutingTable_ConcurrentAccess(t *testing.T) {
 		t.Errorf("Expected 100 resources after concurrent writes, got %d", len(resources))
 	}
 }
+
+func TestRoutingTable_ToolCollisions(t *testing.T) {
+	backendA := BackendInfo{Name: "fs-a", Group: "developer"}
+	backendB := BackendInfo{Name: "fs-b", Group: "developer"}
+
+	// The winner does not depend on discovery order
+	for _, order := range [][]BackendInfo{{backendA, backendB}, {backendB, backendA}} {
+		rt := NewRoutingTable()
+		for _, info := range order {
+			rt.setBackendTools(info, config.NamespaceNone, []string{"search", "read_" + info.Name}, nil)
+		}
+
+		if backend, _ := rt.FindToolBackend("search"); backend != "fs-a" {
+			t.Errorf("Expected search to route to fs-a, got %s", backend)
+		}
+
+		collisions := rt.ToolCollisions()
+		if len(collisions) != 1 || len(collisions["search"]) != 2 || collisions["search"][0] != "fs-a" {
+			t.Errorf("Unexpected collisions: %v", collisions)
+		}
+
+		// The shadowed tool is still reachable by its qualified names
+		for _, name := range []string{"fs-b.search", "developer/fs-b/search"} {
+			route, exists := rt.ResolveTool(name)
+			if !exists || route.Backend != "fs-b" || route.Tool != "search" {
+				t.Errorf("Expected %s to resolve to fs-b/search, got %+v (exists=%t)", name, route, exists)
+			}
+		}
+	}
+}
+
+func TestRoutingTable_Namespacing(t *testing.T) {
+	tests := []struct {
+		namespace string
+		expected  []string
+	}{
+		{config.NamespaceNone, []string{"search"}},
+		{config.NamespaceBackend, []string{"fs-a.search", "fs-b.search"}},
+		{config.NamespaceGroup, []string{"developer/fs-a/search", "developer/fs-b/search"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			rt := NewRoutingTable()
+			rt.setBackendTools(BackendInfo{Name: "fs-a", Group: "developer"}, tt.namespace, []string{"search"}, nil)
+			rt.setBackendTools(BackendInfo{Name: "fs-b", Group: "developer"}, tt.namespace, []string{"search"}, nil)
+
+			tools := rt.GetAllTools()
+			if len(tools) != len(tt.expected) {
+				t.Fatalf("Expected tools %v, got %v", tt.expected, tools)
+			}
+			for i, name := range tt.expected {
+				if tools[i] != name {
+					t.Errorf("Expected tools %v, got %v", tt.expected, tools)
+				}
+				route, exists := rt.ResolveTool(name)
+				if !exists || route.Tool != "search" {
+					t.Errorf("Expected %s to resolve to the search tool, got %+v", name, route)
+				}
+			}
+
+			if tt.namespace != config.NamespaceNone && len(rt.ToolCollisions()) != 0 {
+				t.Errorf("Namespaced tools should not collide: %v", rt.ToolCollisions())
+			}
+		})
+	}
+}
+
+func TestRoutingTable_SetBackendToolsReportsChanges(t *testing.T) {
+	rt := NewRoutingTable()
+	info := BackendInfo{Name: "fs-a", Group: "developer"}
+
+	if !rt.setBackendTools(info, config.NamespaceNone, []string{"search"}, nil) {
+		t.Error("Expected adding tools to be a change")
+	}
+	if rt.setBackendTools(info, config.NamespaceNone, []string{"search"}, nil) {
+		t.Error("Expected the same tools not to be a change")
+	}
+	if !rt.setBackendTools(info, config.NamespaceNone, nil, nil) {
+		t.Error("Expected removing tools to be a change")
+	}
+	if _, exists := rt.ResolveTool("search"); exists {
+		t.Error("Removed tool should no longer resolve")
+	}
+}
+
+func TestRoutingTable_ToolOverrides(t *testing.T) {
+	rt := NewRoutingTable()
+	fsA := BackendInfo{Name: "fs-a", Group: "developer"}
+	fsB := BackendInfo{Name: "fs-b", Group: "developer"}
+
+	rt.setBackendTools(fsA, config.NamespaceNone, []string{"read", "write"}, map[string]config.ToolOverride{
+		"read": {Tool: "read", Name: "fs_read", Aliases: []string{"cat", "write"}},
+	})
+	rt.setBackendTools(fsB, config.NamespaceNone, []string{"read"}, nil)
+
+	tools := rt.GetAllTools()
+	if !slices.Equal(tools, []string{"fs_read", "read", "write"}) {
+		t.Errorf("Expected aliases not to be listed, got %v", tools)
+	}
+
+	tests := []struct {
+		name    string
+		backend string
+		tool    string
+	}{
+		{"fs_read", "fs-a", "read"},
+		{"cat", "fs-a", "read"},
+		{"read", "fs-b", "read"},
+		{"fs-a.read", "fs-a", "read"},
+		{"fs-a.fs_read", "fs-a", "read"},
+		{"developer/fs-a/fs_read", "fs-a", "read"},
+		// Aliases yield to exposed names
+		{"write", "fs-a", "write"},
+	}
+	for _, tt := range tests {
+		route, exists := rt.ResolveTool(tt.name)
+		if !exists || route.Backend != tt.backend || route.Tool != tt.tool {
+			t.Errorf("Expected %s to resolve to %s/%s, got %+v (exists=%t)", tt.name, tt.backend, tt.tool, route, exists)
+		}
+	}
+
+	if len(rt.ToolCollisions()) != 0 {
+		t.Errorf("Renamed tools should not collide: %v", rt.ToolCollisions())
+	}
+}
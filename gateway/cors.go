@@ -0,0 +1,80 @@
+package gateway
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Headers browsers may send and read on cross-origin MCP requests
+var (
+	corsAllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodOptions}
+	corsAllowedHeaders = []string{"Accept", "Authorization", "Content-Type", "Last-Event-ID", "Mcp-Protocol-Version", "Mcp-Session-Id"}
+	corsExposedHeaders = []string{"Mcp-Session-Id", "WWW-Authenticate"}
+)
+
+// corsMaxAge is how long, in seconds, browsers may cache a preflight result
+const corsMaxAge = "86400"
+
+// CORS lets browser-based MCP clients on allowed origins call the gateway's
+// endpoints. Requests from other origins get no CORS headers, so browsers
+// refuse to hand their responses to the page.
+type CORS struct {
+	allowedOrigins []string
+	allowAll       bool
+}
+
+// NewCORS creates a CORS middleware for the given origins; "*" allows any
+// origin and no origins allow only same-origin requests
+func NewCORS(allowedOrigins []string) *CORS {
+	return &CORS{
+		allowedOrigins: allowedOrigins,
+		allowAll:       slices.Contains(allowedOrigins, "*"),
+	}
+}
+
+// allows reports whether requests from origin are allowed
+func (c *CORS) allows(origin string) bool {
+	return c.allowAll || slices.Contains(c.allowedOrigins, origin)
+}
+
+// Middleware adds CORS headers to responses for allowed origins and answers
+// preflight requests itself, before they reach handlers requiring
+// authentication: browsers never send credentials with a preflight.
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r) // Not a cross-origin request
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if !c.allows(origin) {
+			if preflight {
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Echo the origin rather than "*" so that responses can vary by origin
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(corsAllowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsAllowedHeaders, ", "))
+			w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,84 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCORS_Middleware(t *testing.T) {
+	reached := false
+	handler := NewCORS([]string{"https://app.example.com"}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.Header().Set("Mcp-Session-Id", "session-1")
+	}))
+
+	tests := []struct {
+		name        string
+		method      string
+		origin      string
+		preflight   bool
+		wantStatus  int
+		wantOrigin  string
+		wantReached bool
+	}{
+		{"same origin", http.MethodPost, "", false, http.StatusOK, "", true},
+		{"allowed origin", http.MethodPost, "https://app.example.com", false, http.StatusOK, "https://app.example.com", true},
+		{"other origin", http.MethodPost, "https://evil.example.com", false, http.StatusOK, "", true},
+		{"allowed preflight", http.MethodOptions, "https://app.example.com", true, http.StatusNoContent, "https://app.example.com", false},
+		{"rejected preflight", http.MethodOptions, "https://evil.example.com", true, http.StatusForbidden, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached = false
+			req := httptest.NewRequest(tt.method, "/mcp", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+				req.Header.Set("Access-Control-Request-Headers", "authorization, content-type, mcp-session-id")
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Expected allowed origin %q, got %q", tt.wantOrigin, got)
+			}
+			if reached != tt.wantReached {
+				t.Errorf("Expected handler reached=%t, got %t", tt.wantReached, reached)
+			}
+
+			if tt.wantOrigin == "" {
+				return
+			}
+			if tt.preflight {
+				for _, header := range []string{"Authorization", "Mcp-Session-Id"} {
+					if !strings.Contains(rec.Header().Get("Access-Control-Allow-Headers"), header) {
+						t.Errorf("Expected preflight to allow the %s header", header)
+					}
+				}
+			} else if !strings.Contains(rec.Header().Get("Access-Control-Expose-Headers"), "Mcp-Session-Id") {
+				t.Error("Expected Mcp-Session-Id to be exposed")
+			}
+		})
+	}
+}
+
+func TestCORS_AllowAll(t *testing.T) {
+	handler := NewCORS([]string{"*"}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/sse", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:5173" {
+		t.Errorf("Expected any origin to be allowed, got %q", got)
+	}
+}
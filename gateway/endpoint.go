@@ -0,0 +1,219 @@
+package gateway
+
+import (
+	"log/slog"
+	"net/url"
+	"reflect"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// mcpEndpoint is an MCP server exposing the meta-tools, the resources and
+// the prompts of a set of backends: every backend of the gateway, or those
+// of a single group
+type mcpEndpoint struct {
+	name            string // group name, empty for the gateway-wide endpoint
+	metaToolHandler *MetaToolHandler
+	resourceHandler *ResourceHandler
+	promptHandler   *PromptHandler
+	capabilities    GatewayCapabilities
+	pageSize        int // items per page of the server's lists, zero for the SDK default
+	server          *mcp.Server
+	resources       map[string]*mcp.Resource         // resource URI -> resource registered on the server
+	templates       map[string]*mcp.ResourceTemplate // URI template -> template registered on the server
+	prompts         map[string]*mcp.Prompt           // prompt name -> prompt registered on the server
+}
+
+// newMCPEndpoint creates an endpoint whose meta-tools route through handler
+// and whose resources and prompts are served by resourceHandler and
+// promptHandler. Its server is created by start.
+func newMCPEndpoint(name string, handler *MetaToolHandler, resourceHandler *ResourceHandler, promptHandler *PromptHandler) *mcpEndpoint {
+	return &mcpEndpoint{
+		name:            name,
+		metaToolHandler: handler,
+		resourceHandler: resourceHandler,
+		promptHandler:   promptHandler,
+		resources:       make(map[string]*mcp.Resource),
+		templates:       make(map[string]*mcp.ResourceTemplate),
+		prompts:         make(map[string]*mcp.Prompt),
+	}
+}
+
+// start creates the endpoint's MCP server and registers the meta-tools,
+// resources and prompts if the backends provide them
+func (e *mcpEndpoint) start(capabilities GatewayCapabilities) {
+	e.capabilities = capabilities
+	e.server = mcp.NewServer(
+		&mcp.Implementation{
+			Name:    "mcp-gateway",
+			Version: "1.0.0",
+		},
+		e.serverOptions(),
+	)
+	e.server.AddReceivingMiddleware(e.resourceHandler.filterResources, e.promptHandler.filterPrompts)
+
+	if capabilities.Tools {
+		e.registerMetaTools()
+	}
+	if capabilities.Resources {
+		e.syncResources()
+	}
+	if capabilities.Prompts {
+		e.syncPrompts()
+	}
+}
+
+// serverOptions returns the options of the endpoint's server. Resource
+// subscriptions are offered when the gateway can forward them to backends.
+func (e *mcpEndpoint) serverOptions() *mcp.ServerOptions {
+	opts := &mcp.ServerOptions{PageSize: e.pageSize}
+	if e.resourceHandler.subscriptions != nil {
+		opts.SubscribeHandler = e.resourceHandler.HandleSubscribe
+		opts.UnsubscribeHandler = e.resourceHandler.HandleUnsubscribe
+	}
+	return opts
+}
+
+// apply merges newly discovered capabilities into the endpoint's. The
+// meta-tools are registered when the first backend to provide tools appears.
+// Resources and prompts are registered again when a backend's changed.
+// It reports whether clients should be told that the tool list changed.
+func (e *mcpEndpoint) apply(capabilities GatewayCapabilities, changes DiscoveryChanges) bool {
+	registered := false
+	if capabilities.Tools && !e.capabilities.Tools {
+		e.registerMetaTools()
+		registered = true
+	}
+	if changes.Resources {
+		e.syncResources()
+	}
+	if changes.Prompts {
+		e.syncPrompts()
+	}
+	e.capabilities = e.capabilities.merge(capabilities)
+	return changes.Tools && e.capabilities.Tools && !registered
+}
+
+// notifyToolListChanged sends notifications/tools/list_changed to the
+// endpoint's clients, prompting them to call list_tools again. The SDK only
+// sends the notification when a tool is added, so list_tools is registered
+// again.
+func (e *mcpEndpoint) notifyToolListChanged() {
+	mcp.AddTool(e.server, listToolsTool(), e.metaToolHandler.HandleListTools)
+}
+
+// registerMetaTools registers the three meta-tools
+func (e *mcpEndpoint) registerMetaTools() {
+	// Register list_tools meta-tool
+	mcp.AddTool(e.server, listToolsTool(), e.metaToolHandler.HandleListTools)
+
+	// Register describe_tool meta-tool
+	describeToolTool := &mcp.Tool{
+		Name:        "describe_tool",
+		Description: "指定したツールの詳細情報（説明、引数仕様）を取得",
+	}
+	mcp.AddTool(e.server, describeToolTool, e.metaToolHandler.HandleDescribeTool)
+
+	// Register call_tool meta-tool
+	callToolTool := &mcp.Tool{
+		Name:        "call_tool",
+		Description: "実際のツール実行を行う",
+	}
+	mcp.AddTool(e.server, callToolTool, e.metaToolHandler.HandleCallTool)
+
+	if e.name == "" {
+		slog.Info("Registered meta-tools", "tools", []string{"list_tools", "describe_tool", "call_tool"})
+	} else {
+		slog.Info("Registered meta-tools", "group", e.name, "tools", []string{"list_tools", "describe_tool", "call_tool"})
+	}
+}
+
+// syncResources registers the resources and resource templates of the
+// endpoint's backends on its server, replacing those whose description
+// changed and removing those no backend lists any more. The SDK tells
+// clients that the list changed.
+func (e *mcpEndpoint) syncResources() {
+	current := make(map[string]*mcp.Resource)
+	for _, resource := range e.resourceHandler.routingTable.GetResources() {
+		if _, err := url.Parse(resource.URI); err != nil {
+			slog.Warn("Skipping resource with invalid URI", "uri", resource.URI, "error", err)
+			continue
+		}
+		current[resource.URI] = resource
+	}
+
+	var removed []string
+	for uri := range e.resources {
+		if _, exists := current[uri]; !exists {
+			removed = append(removed, uri)
+		}
+	}
+	if len(removed) > 0 {
+		e.server.RemoveResources(removed...)
+	}
+
+	for uri, resource := range current {
+		if !reflect.DeepEqual(e.resources[uri], resource) {
+			e.server.AddResource(resource, e.resourceHandler.HandleReadResource)
+		}
+	}
+	e.resources = current
+
+	// The SDK only reads URIs matching a registered template; the handler
+	// routes them by the most specific template of any backend
+	templates := make(map[string]*mcp.ResourceTemplate)
+	for _, template := range e.resourceHandler.routingTable.GetResourceTemplates() {
+		templates[template.URITemplate] = template
+	}
+
+	removed = nil
+	for uriTemplate := range e.templates {
+		if _, exists := templates[uriTemplate]; !exists {
+			removed = append(removed, uriTemplate)
+		}
+	}
+	if len(removed) > 0 {
+		e.server.RemoveResourceTemplates(removed...)
+	}
+
+	for uriTemplate, template := range templates {
+		if !reflect.DeepEqual(e.templates[uriTemplate], template) {
+			e.server.AddResourceTemplate(template, e.resourceHandler.HandleReadResource)
+		}
+	}
+	e.templates = templates
+}
+
+// syncPrompts registers the prompts of the endpoint's backends on its
+// server like syncResources does for resources
+func (e *mcpEndpoint) syncPrompts() {
+	current := make(map[string]*mcp.Prompt)
+	for _, prompt := range e.promptHandler.routingTable.GetPrompts() {
+		current[prompt.Name] = prompt
+	}
+
+	var removed []string
+	for name := range e.prompts {
+		if _, exists := current[name]; !exists {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) > 0 {
+		e.server.RemovePrompts(removed...)
+	}
+
+	for name, prompt := range current {
+		if !reflect.DeepEqual(e.prompts[name], prompt) {
+			e.server.AddPrompt(prompt, e.promptHandler.HandleGetPrompt)
+		}
+	}
+	e.prompts = current
+}
+
+// listToolsTool returns the definition of the list_tools meta-tool
+func listToolsTool() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "list_tools",
+		Description: "バックエンドから利用可能なツールの名前一覧を取得",
+	}
+}
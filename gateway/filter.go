@@ -0,0 +1,31 @@
+package gateway
+
+import (
+	"path"
+)
+
+// toolFilter selects which of a backend's tools are exposed, using glob
+// patterns as understood by path.Match
+type toolFilter struct {
+	include []string
+	exclude []string
+}
+
+// allows reports whether a tool is exposed: it must match an include pattern,
+// if there are any, and no exclude pattern
+func (f toolFilter) allows(toolName string) bool {
+	if len(f.include) > 0 && !matchesAny(f.include, toolName) {
+		return false
+	}
+	return !matchesAny(f.exclude, toolName)
+}
+
+// matchesAny reports whether name matches one of the glob patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
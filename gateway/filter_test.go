@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+func TestToolFilter_Allows(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  toolFilter
+		allowed []string
+		denied  []string
+	}{
+		{
+			name:    "no patterns",
+			filter:  toolFilter{},
+			allowed: []string{"git_status", "git_push"},
+		},
+		{
+			name:    "exclude only",
+			filter:  toolFilter{exclude: []string{"git_push", "delete_*"}},
+			allowed: []string{"git_status", "read_file"},
+			denied:  []string{"git_push", "delete_file", "delete_directory"},
+		},
+		{
+			name:    "include only",
+			filter:  toolFilter{include: []string{"git_*"}},
+			allowed: []string{"git_status", "git_push"},
+			denied:  []string{"read_file"},
+		},
+		{
+			name:    "exclude wins over include",
+			filter:  toolFilter{include: []string{"git_*"}, exclude: []string{"git_push*"}},
+			allowed: []string{"git_status", "git_log"},
+			denied:  []string{"git_push", "git_push_tags", "read_file"},
+		},
+		{
+			name:    "character classes",
+			filter:  toolFilter{include: []string{"read_[a-f]*"}},
+			allowed: []string{"read_file", "read_dir"},
+			denied:  []string{"read_url"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range tt.allowed {
+				if !tt.filter.allows(name) {
+					t.Errorf("Expected %s to be allowed", name)
+				}
+			}
+			for _, name := range tt.denied {
+				if tt.filter.allows(name) {
+					t.Errorf("Expected %s to be hidden", name)
+				}
+			}
+		})
+	}
+}
+
+func TestGateway_HidesFilteredTools(t *testing.T) {
+	backendServer := mcp.NewServer(&mcp.Implementation{Name: "git", Version: "1.0.0"}, nil)
+	for _, name := range []string{"git_status", "git_log", "git_push", "delete_file"} {
+		addGreetTool(backendServer, name)
+	}
+	ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return backendServer }, nil))
+	defer ts.Close()
+
+	cfg := &config.Config{
+		Groups: []config.Group{
+			{
+				Name: "developer",
+				Backends: map[string]config.Backend{
+					"git-tools": {
+						Name:      "git-tools",
+						Transport: "http",
+						Endpoint:  ts.URL,
+						Exclude:   []string{"git_push", "delete_*"},
+					},
+				},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	ctx := context.Background()
+	if err := gateway.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+
+	tools := gateway.GetRoutingTable().GetAllTools()
+	if len(tools) != 2 || tools[0] != "git_log" || tools[1] != "git_status" {
+		t.Errorf("Expected only git_log and git_status to be routed, got %v", tools)
+	}
+	if tools := gateway.GetGroupRoutingTable("developer").GetAllTools(); len(tools) != 2 {
+		t.Errorf("Expected the group routing table to be filtered as well, got %v", tools)
+	}
+
+	// Hidden tools cannot be called, not even by their qualified name
+	session := connectTestClient(t, gateway.GetServer(), nil)
+	for _, name := range []string{"git_push", "git-tools.git_push", "developer/git-tools/delete_file"} {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "call_tool",
+			Arguments: map[string]interface{}{"tool_name": name, "arguments": map[string]interface{}{}},
+		})
+		if err != nil {
+			t.Fatalf("call_tool failed: %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("Calling hidden tool %s should fail", name)
+		}
+	}
+}
@@ -3,7 +3,8 @@ package gateway
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/takutakahashi/awesome-mcp-proxy/config"
@@ -14,10 +15,15 @@ type Gateway struct {
 	config             *config.Config
 	backendManager     *BackendManager
 	capabilityDiscover *CapabilityDiscoverer
-	metaToolHandler    *MetaToolHandler
 	routingTable       *RoutingTable
-	capabilities       GatewayCapabilities
-	server             *mcp.Server
+	notifications      *NotificationRouter
+	subscriptions      *subscriptionManager
+	refresher          *capabilityRefresher
+	authorizer         *Authorizer             // nil if authentication is disabled
+	cache              *responseCache          // nil if caching is disabled
+	endpoint           *mcpEndpoint            // serves every backend
+	groups             map[string]*mcpEndpoint // group name -> endpoint serving the group
+	mu                 sync.RWMutex
 }
 
 // NewGateway creates a new Gateway instance
@@ -25,27 +31,44 @@ func NewGateway(cfg *config.Config) (*Gateway, error) {
 	// Create backend manager
 	backendManager := NewBackendManager()
 
+	// Create router for messages sent by backends on their own initiative
+	notifications := NewNotificationRouter()
+
 	// Initialize backends from config
 	for _, group := range cfg.Groups {
 		for _, backendCfg := range group.Backends {
 			var backend Backend
+			backendCfg.Timeout = cfg.TimeoutFor(backendCfg)
 
 			switch backendCfg.Transport {
 			case "http":
 				backend = NewHTTPBackend(backendCfg, group.Name)
 			case "stdio":
 				backend = NewStdioBackend(backendCfg, group.Name)
+			case "sse":
+				backend = NewSSEBackend(backendCfg, group.Name)
+			case "websocket":
+				backend = NewWebSocketBackend(backendCfg, group.Name)
 			default:
 				return nil, fmt.Errorf("unsupported transport type: %s", backendCfg.Transport)
 			}
 
+			backend.SetMessageHandler(notifications)
+			notifications.SetBackendGroup(backendCfg.Name, group.Name)
 			backendManager.AddBackend(backend)
-			log.Printf("Added %s backend: %s (group: %s)", backendCfg.Transport, backendCfg.Name, group.Name)
+			slog.Info("Added backend", "backend", backendCfg.Name, "group", group.Name, "transport", backendCfg.Transport)
 		}
 	}
 
 	// Create capability discoverer
 	capabilityDiscover := NewCapabilityDiscoverer(backendManager)
+	for _, group := range cfg.Groups {
+		capabilityDiscover.SetNamespace(group.Name, cfg.NamespaceFor(group))
+		for _, backendCfg := range group.Backends {
+			capabilityDiscover.SetToolFilter(backendCfg.Name, backendCfg.Include, backendCfg.Exclude)
+			capabilityDiscover.SetToolOverrides(backendCfg.Name, backendCfg.Tools)
+		}
+	}
 
 	// Create gateway
 	gateway := &Gateway{
@@ -53,17 +76,82 @@ func NewGateway(cfg *config.Config) (*Gateway, error) {
 		backendManager:     backendManager,
 		capabilityDiscover: capabilityDiscover,
 		routingTable:       capabilityDiscover.GetRoutingTable(),
+		notifications:      notifications,
+		subscriptions:      newSubscriptionManager(backendManager),
+		groups:             make(map[string]*mcpEndpoint),
+	}
+
+	// Restrict groups to the clients their access policies allow
+	if cfg.Middleware.Auth.Enabled {
+		gateway.authorizer = NewAuthorizer(cfg.Groups)
+		notifications.SetAuthorizer(gateway.authorizer)
 	}
 
-	// Create meta-tool handler
-	gateway.metaToolHandler = NewMetaToolHandler(backendManager, gateway.routingTable)
+	// Cache tool lists and results of idempotent tools until they change
+	if cfg.Middleware.Caching.Enabled && cfg.Middleware.Caching.TTL > 0 {
+		gateway.cache = newResponseCache(cfg.Middleware.Caching.TTL)
+		notifications.OnListChanged(gateway.cache.handleListChanged)
+	}
+
+	// Create meta-tool, resource and prompt handlers for the gateway-wide
+	// and per-group endpoints
+	gateway.endpoint = gateway.newEndpoint("", gateway.routingTable)
+	for _, group := range cfg.Groups {
+		gateway.groups[group.Name] = gateway.newEndpoint(group.Name, capabilityDiscover.GetGroupRoutingTable(group.Name))
+	}
+
+	// Re-discover capabilities periodically and when backends report changes
+	gateway.refresher = newCapabilityRefresher(gateway, cfg.Gateway.RefreshInterval)
+	notifications.OnListChanged(gateway.refresher.handleListChanged)
+
+	// Refresh routing for backends that started a new session on their own,
+	// e.g. a restarted stdio process or an expired HTTP session
+	for _, backend := range backendManager.GetAllBackends() {
+		if notifier, ok := backend.(RestartNotifier); ok {
+			notifier.SetRestartHandler(func(ctx context.Context, result *mcp.InitializeResult) {
+				gateway.refreshBackend(ctx, backend, result)
+			})
+		}
+	}
 
 	return gateway, nil
 }
 
+// newEndpoint creates an endpoint whose handlers route through routingTable
+func (g *Gateway) newEndpoint(name string, routingTable *RoutingTable) *mcpEndpoint {
+	endpoint := newMCPEndpoint(name, g.newMetaToolHandler(routingTable), g.newResourceHandler(routingTable), g.newPromptHandler(routingTable))
+	endpoint.pageSize = g.config.Gateway.PageSize
+	return endpoint
+}
+
+// newMetaToolHandler creates a meta-tool handler routing through routingTable
+func (g *Gateway) newMetaToolHandler(routingTable *RoutingTable) *MetaToolHandler {
+	handler := NewMetaToolHandler(g.backendManager, routingTable)
+	handler.notifications = g.notifications
+	handler.authorizer = g.authorizer
+	handler.cache = g.cache
+	handler.pageSize = g.config.Gateway.PageSize
+	return handler
+}
+
+// newResourceHandler creates a resource handler routing through routingTable
+func (g *Gateway) newResourceHandler(routingTable *RoutingTable) *ResourceHandler {
+	handler := NewResourceHandler(g.backendManager, routingTable)
+	handler.authorizer = g.authorizer
+	handler.subscriptions = g.subscriptions
+	return handler
+}
+
+// newPromptHandler creates a prompt handler routing through routingTable
+func (g *Gateway) newPromptHandler(routingTable *RoutingTable) *PromptHandler {
+	handler := NewPromptHandler(g.backendManager, routingTable)
+	handler.authorizer = g.authorizer
+	return handler
+}
+
 // Initialize initializes the gateway and discovers backend capabilities
 func (g *Gateway) Initialize(ctx context.Context) error {
-	log.Println("Initializing MCP Gateway...")
+	slog.Info("Initializing MCP Gateway")
 
 	// Discover capabilities from all backends
 	capabilities, err := g.capabilityDiscover.DiscoverCapabilities(ctx)
@@ -71,118 +159,142 @@ func (g *Gateway) Initialize(ctx context.Context) error {
 		return fmt.Errorf("failed to discover capabilities: %w", err)
 	}
 
-	g.capabilities = capabilities
-	log.Printf("Gateway capabilities: tools=%t, resources=%t, prompts=%t",
-		capabilities.Tools, capabilities.Resources, capabilities.Prompts)
-
-	// Create MCP server
-	g.server = mcp.NewServer(
-		&mcp.Implementation{
-			Name:    "mcp-gateway",
-			Version: "1.0.0",
-		},
-		nil,
-	)
+	slog.Info("Gateway capabilities discovered",
+		"tools", capabilities.Tools, "resources", capabilities.Resources, "prompts", capabilities.Prompts)
 
-	// Register meta-tools if tools capability is enabled
-	if capabilities.Tools {
-		g.registerMetaTools()
+	if collisions := g.routingTable.ToolCollisions(); len(collisions) > 0 {
+		slog.Warn("Tool names are provided by more than one backend; configure a namespace strategy to expose them all", "collisions", len(collisions))
 	}
 
-	// Register resource and prompt handlers
-	// TODO: Implement dynamic resource and prompt aggregation
-	// For now, focus on meta-tools functionality
-	// if capabilities.Resources {
-	// 	g.registerResourceHandlers()
-	// }
+	// Backends refreshed in the background may register meta-tools as well
+	g.mu.Lock()
+	g.endpoint.start(capabilities)
+	g.notifications.AddServer(g.endpoint.server)
+
+	// Each group gets its own server exposing only the group's backends
+	for name, endpoint := range g.groups {
+		endpoint.start(g.capabilityDiscover.GroupCapabilities(name))
+		g.notifications.AddGroupServer(name, endpoint.server)
+	}
+	g.mu.Unlock()
 
-	// if capabilities.Prompts {
-	// 	g.registerPromptHandlers()
-	// }
+	// Watch backends so that those that were down come back into service
+	healthChecks := make(map[string]config.HealthCheckConfig)
+	for _, group := range g.config.Groups {
+		for _, backendCfg := range group.Backends {
+			healthChecks[backendCfg.Name] = backendCfg.HealthCheck
+		}
+	}
+	g.backendManager.StartHealthChecks(healthChecks, g.refreshBackend)
+	g.refresher.start()
 
 	return nil
 }
 
-// registerMetaTools registers the three meta-tools
-func (g *Gateway) registerMetaTools() {
-	// Register list_tools meta-tool
-	listToolsTool := &mcp.Tool{
-		Name:        "list_tools",
-		Description: "バックエンドから利用可能なツールの名前一覧を取得",
-	}
-	mcp.AddTool(g.server, listToolsTool, g.metaToolHandler.HandleListTools)
-
-	// Register describe_tool meta-tool
-	describeToolTool := &mcp.Tool{
-		Name:        "describe_tool",
-		Description: "指定したツールの詳細情報（説明、引数仕様）を取得",
-	}
-	mcp.AddTool(g.server, describeToolTool, g.metaToolHandler.HandleDescribeTool)
-
-	// Register call_tool meta-tool
-	callToolTool := &mcp.Tool{
-		Name:        "call_tool",
-		Description: "実際のツール実行を行う",
-	}
-	mcp.AddTool(g.server, callToolTool, g.metaToolHandler.HandleCallTool)
-
-	log.Println("Registered meta-tools: list_tools, describe_tool, call_tool")
-}
-
-// TODO: Implement resource and prompt handlers in the future
-// registerResourceHandlers and registerPromptHandlers will be added
-// when dynamic resource and prompt aggregation is fully implemented
-
-// handleResourcesList aggregates resources from all backends (currently unused)
-// func (g *Gateway) handleResourcesList(ctx context.Context, request *mcp.ListResourcesRequest) (*mcp.ListResourcesResult, error) {
-//	var allResources []mcp.Resource
-//
-//	backends := g.backendManager.GetHealthyBackends()
-//	for _, backend := range backends {
-//		backendInfo := backend.GetInfo()
-//
-//		// Check if backend supports resources
-//		response, err := backend.SendRequest(ctx, "resources/list", struct{}{})
-//		if err != nil {
-//			log.Printf("Failed to get resources from backend %s: %v", backendInfo.Name, err)
-//			continue
-//		}
-//
-//		var resourcesResponse struct {
-//			Resources []mcp.Resource `json:"resources"`
-//		}
-//
-//		if err := json.Unmarshal(*response, &resourcesResponse); err != nil {
-//			log.Printf("Failed to unmarshal resources from backend %s: %v", backendInfo.Name, err)
-//			continue
-//		}
-//
-//		allResources = append(allResources, resourcesResponse.Resources...)
-//	}
-//
-//	return &mcp.ListResourcesResult{
-//		Resources: allResources,
-//	}, nil
-//}
-
-// TODO: Implement handleResourceRead and handlePromptGet when resource/prompt support is added
+// refreshBackend re-discovers the capabilities of a backend, e.g. after it
+// was restarted, came back up or reported a list change. result is the
+// backend's new initialize result, or nil if it was not re-initialized.
+func (g *Gateway) refreshBackend(ctx context.Context, backend Backend, result *mcp.InitializeResult) {
+	backendInfo := backend.GetInfo()
+	slog.Info("Re-discovering backend capabilities", "backend", backendInfo.Name, "group", backendInfo.Group)
+	if g.cache != nil {
+		g.cache.invalidateBackend(backendInfo.Name)
+	}
+	capabilities, changes := g.capabilityDiscover.RefreshBackend(ctx, backend, result)
+	g.applyDiscovery(backendInfo.Group, capabilities, changes)
+
+	// A re-initialized backend forgot the resources the gateway subscribed to
+	if result != nil {
+		g.subscriptions.resubscribe(ctx, backendInfo.Name)
+	}
+}
+
+// refreshAll re-discovers the capabilities of every healthy backend
+func (g *Gateway) refreshAll(ctx context.Context) {
+	for _, backend := range g.backendManager.GetHealthyBackends() {
+		capabilities, changes := g.capabilityDiscover.RefreshBackend(ctx, backend, nil)
+		g.applyDiscovery(backend.GetInfo().Group, capabilities, changes)
+	}
+}
+
+// applyDiscovery merges the newly discovered capabilities of a backend into
+// those of the gateway-wide endpoint and of the backend's group endpoint,
+// and tells their clients about changed lists
+func (g *Gateway) applyDiscovery(group string, capabilities GatewayCapabilities, changes DiscoveryChanges) {
+	g.mu.Lock()
+	if g.endpoint.server == nil {
+		g.mu.Unlock()
+		return // Not serving clients yet
+	}
+
+	var notify []*mcpEndpoint
+	endpoints := []*mcpEndpoint{g.endpoint}
+	if endpoint, exists := g.groups[group]; exists {
+		endpoints = append(endpoints, endpoint)
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.apply(capabilities, changes) {
+			notify = append(notify, endpoint)
+		}
+	}
+	g.mu.Unlock()
+
+	for _, endpoint := range notify {
+		endpoint.notifyToolListChanged()
+	}
+}
 
 // GetServer returns the underlying MCP server
 func (g *Gateway) GetServer() *mcp.Server {
-	return g.server
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.endpoint.server
+}
+
+// GetGroupServer returns the MCP server exposing only a group's backends, or
+// nil if the group does not exist or the gateway is not initialized
+func (g *Gateway) GetGroupServer(name string) *mcp.Server {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	endpoint, exists := g.groups[name]
+	if !exists {
+		return nil
+	}
+	return endpoint.server
 }
 
 // GetCapabilities returns the gateway capabilities
 func (g *Gateway) GetCapabilities() GatewayCapabilities {
-	return g.capabilities
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.endpoint.capabilities
+}
+
+// GetGroupCapabilities returns the capabilities of a group's endpoint
+func (g *Gateway) GetGroupCapabilities(name string) GatewayCapabilities {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	endpoint, exists := g.groups[name]
+	if !exists {
+		return GatewayCapabilities{}
+	}
+	return endpoint.capabilities
 }
 
 // Close closes the gateway and all backends
 func (g *Gateway) Close() error {
-	log.Println("Closing MCP Gateway...")
+	slog.Info("Closing MCP Gateway")
+	g.refresher.stop()
 	return g.backendManager.Close()
 }
 
+// GetNotificationRouter returns the router for backend-originated messages
+func (g *Gateway) GetNotificationRouter() *NotificationRouter {
+	return g.notifications
+}
+
 // GetBackendManager returns the backend manager (for testing)
 func (g *Gateway) GetBackendManager() *BackendManager {
 	return g.backendManager
@@ -192,3 +304,8 @@ func (g *Gateway) GetBackendManager() *BackendManager {
 func (g *Gateway) GetRoutingTable() *RoutingTable {
 	return g.routingTable
 }
+
+// GetGroupRoutingTable returns the routing table of a group (for testing)
+func (g *Gateway) GetGroupRoutingTable(name string) *RoutingTable {
+	return g.capabilityDiscover.GetGroupRoutingTable(name)
+}
@@ -2,8 +2,15 @@ package gateway
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/takutakahashi/awesome-mcp-proxy/config"
 )
 
@@ -106,10 +113,12 @@ func TestGateway_Initialize_NoBackends(t *testing.T) {
 
 func TestGateway_GetCapabilities(t *testing.T) {
 	gateway := &Gateway{
-		capabilities: GatewayCapabilities{
-			Tools:     true,
-			Resources: false,
-			Prompts:   true,
+		endpoint: &mcpEndpoint{
+			capabilities: GatewayCapabilities{
+				Tools:     true,
+				Resources: false,
+				Prompts:   true,
+			},
 		},
 	}
 
@@ -298,3 +307,139 @@ func TestGateway_Close(t *testing.T) {
 		t.Errorf("Second close should not return error: %v", err)
 	}
 }
+
+func TestGateway_RefreshesRoutingAfterRestart(t *testing.T) {
+	toolsFile := filepath.Join(t.TempDir(), "tools")
+	if err := os.WriteFile(toolsFile, []byte("echo"), 0644); err != nil {
+		t.Fatalf("Failed to write tools file: %v", err)
+	}
+
+	backendCfg := helperBackendConfig("stdio-backend")
+	backendCfg.Env[stdioHelperToolsFileEnv] = toolsFile
+	backendCfg.Restart = config.RestartConfig{Backoff: 10 * time.Millisecond}
+
+	cfg := &config.Config{
+		Groups: []config.Group{
+			{
+				Name:     "test-group",
+				Backends: map[string]config.Backend{"stdio-backend": backendCfg},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	if err := gateway.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+	if _, exists := gateway.GetRoutingTable().FindToolBackend("echo"); !exists {
+		t.Fatal("Expected echo tool to be routed before the restart")
+	}
+
+	// The restarted process reports a different tool set
+	if err := os.WriteFile(toolsFile, []byte("echo2"), 0644); err != nil {
+		t.Fatalf("Failed to write tools file: %v", err)
+	}
+	backend, _ := gateway.GetBackendManager().GetBackend("stdio-backend")
+	crashHelper(t, backend.(*StdioBackend))
+
+	refreshed := waitFor(t, 10*time.Second, func() bool {
+		_, exists := gateway.GetRoutingTable().FindToolBackend("echo2")
+		return exists
+	})
+	if !refreshed {
+		t.Fatal("Routing table was not refreshed after the restart")
+	}
+	if _, exists := gateway.GetRoutingTable().FindToolBackend("echo"); exists {
+		t.Error("Tools removed by the restarted backend should no longer be routed")
+	}
+}
+
+func TestGateway_GroupEndpoints(t *testing.T) {
+	newBackend := func(toolName string) *httptest.Server {
+		server := mcp.NewServer(&mcp.Implementation{Name: toolName, Version: "1.0.0"}, nil)
+		addGreetTool(server, toolName)
+		ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return server }, nil))
+		t.Cleanup(ts.Close)
+		return ts
+	}
+	designBackend := newBackend("export_frame")
+	devBackend := newBackend("git_status")
+
+	cfg := &config.Config{
+		Groups: []config.Group{
+			{
+				Name: "designer",
+				Backends: map[string]config.Backend{
+					"figma-tools": {Name: "figma-tools", Transport: "http", Endpoint: designBackend.URL},
+				},
+			},
+			{
+				Name: "developer",
+				Backends: map[string]config.Backend{
+					"git-tools": {Name: "git-tools", Transport: "http", Endpoint: devBackend.URL},
+				},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	if gateway.GetGroupServer("designer") != nil {
+		t.Error("Group server should be nil before initialization")
+	}
+
+	ctx := context.Background()
+	if err := gateway.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+
+	if gateway.GetGroupServer("unknown") != nil {
+		t.Error("Unknown group should have no server")
+	}
+	if !gateway.GetGroupCapabilities("designer").Tools {
+		t.Error("Designer group should have the tools capability")
+	}
+
+	listTools := func(server *mcp.Server) string {
+		session := connectTestClient(t, server, nil)
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "list_tools", Arguments: map[string]interface{}{}})
+		if err != nil {
+			t.Fatalf("list_tools failed: %v", err)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	designerTools := listTools(gateway.GetGroupServer("designer"))
+	if !strings.Contains(designerTools, "export_frame") || strings.Contains(designerTools, "git_status") {
+		t.Errorf("Designer endpoint should only list design tools, got %s", designerTools)
+	}
+
+	developerTools := listTools(gateway.GetGroupServer("developer"))
+	if !strings.Contains(developerTools, "git_status") || strings.Contains(developerTools, "export_frame") {
+		t.Errorf("Developer endpoint should only list developer tools, got %s", developerTools)
+	}
+
+	allTools := listTools(gateway.GetServer())
+	if !strings.Contains(allTools, "git_status") || !strings.Contains(allTools, "export_frame") {
+		t.Errorf("Gateway endpoint should list every tool, got %s", allTools)
+	}
+
+	// Tools of other groups cannot be called through a group endpoint
+	session := connectTestClient(t, gateway.GetGroupServer("designer"), nil)
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "call_tool",
+		Arguments: map[string]interface{}{"tool_name": "git_status", "arguments": map[string]interface{}{}},
+	})
+	if err == nil && !result.IsError {
+		t.Error("Calling another group's tool should fail")
+	}
+}
@@ -0,0 +1,152 @@
+package gateway
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// Health check defaults
+const (
+	defaultHealthCheckInterval = 30 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+	defaultHealthyThreshold    = 2
+	defaultUnhealthyThreshold  = 3
+
+	// recoveryTimeout bounds the capability discovery run after a recovery
+	recoveryTimeout = 30 * time.Second
+)
+
+// RecoveryHandler is called when a backend that was down passes its health
+// checks again, with the result of the initialize handshake that revived it
+type RecoveryHandler func(ctx context.Context, backend Backend, result *mcp.InitializeResult)
+
+// healthChecker periodically probes a single backend and marks it up or
+// down. A state change needs several consecutive results in the same
+// direction, so a single slow ping does not take a backend out of service.
+type healthChecker struct {
+	backend            Backend
+	interval           time.Duration
+	timeout            time.Duration
+	healthyThreshold   int
+	unhealthyThreshold int
+	onRecover          RecoveryHandler
+
+	up         bool
+	successes  int
+	failures   int
+	initResult *mcp.InitializeResult
+}
+
+// newHealthChecker creates a checker for backend with defaults applied to cfg
+func newHealthChecker(backend Backend, cfg config.HealthCheckConfig, onRecover RecoveryHandler) *healthChecker {
+	hc := &healthChecker{
+		backend:            backend,
+		interval:           cfg.Interval,
+		timeout:            cfg.Timeout,
+		healthyThreshold:   cfg.HealthyThreshold,
+		unhealthyThreshold: cfg.UnhealthyThreshold,
+		onRecover:          onRecover,
+		up:                 backend.IsHealthy(),
+	}
+
+	if hc.interval == 0 {
+		hc.interval = defaultHealthCheckInterval
+	}
+	if hc.timeout == 0 {
+		hc.timeout = defaultHealthCheckTimeout
+	}
+	if hc.healthyThreshold == 0 {
+		hc.healthyThreshold = defaultHealthyThreshold
+	}
+	if hc.unhealthyThreshold == 0 {
+		hc.unhealthyThreshold = defaultUnhealthyThreshold
+	}
+	return hc
+}
+
+// run checks the backend at every interval until ctx is cancelled
+func (hc *healthChecker) run(ctx context.Context) {
+	ticker := time.NewTicker(hc.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			hc.check(ctx)
+		}
+	}
+}
+
+// check probes the backend once and updates its state
+func (hc *healthChecker) check(ctx context.Context) {
+	probeCtx, cancel := context.WithTimeout(ctx, hc.timeout)
+	err := hc.probe(probeCtx)
+	cancel()
+
+	if ctx.Err() != nil {
+		return // Shutting down
+	}
+
+	backendName := hc.backend.GetInfo().Name
+	recovered := false
+
+	if err != nil {
+		hc.successes = 0
+		hc.failures++
+		if hc.up && hc.failures >= hc.unhealthyThreshold {
+			hc.up = false
+			slog.Warn("Backend is down", "backend", backendName, "failed_checks", hc.failures, "error", err)
+		}
+		if !hc.up {
+			hc.initResult = nil // Repeat the handshake on the next check
+		}
+	} else {
+		hc.failures = 0
+		hc.successes++
+		if !hc.up && hc.successes >= hc.healthyThreshold {
+			hc.up = true
+			recovered = true
+			slog.Info("Backend is up again", "backend", backendName, "successful_checks", hc.successes)
+		}
+	}
+
+	// Requests made by the probe flip the transport's own health flag, so a
+	// down backend is held down until it has passed enough checks
+	if !hc.up || recovered {
+		hc.backend.SetHealthy(hc.up)
+	}
+
+	if recovered && hc.onRecover != nil {
+		recoverCtx, cancel := context.WithTimeout(ctx, recoveryTimeout)
+		defer cancel()
+		hc.onRecover(recoverCtx, hc.backend, hc.initResult)
+	}
+}
+
+// probe pings an up backend. A down backend may have lost its session or
+// never completed its handshake, so it is initialized again instead, unless
+// the backend restarts itself and only needs to answer again.
+func (hc *healthChecker) probe(ctx context.Context) error {
+	if restarter, ok := hc.backend.(SelfRestarter); ok && restarter.RestartsItself() {
+		_, err := hc.backend.SendRequest(ctx, "ping", struct{}{})
+		return err
+	}
+
+	if !hc.up && hc.initResult == nil {
+		result, err := hc.backend.Initialize(ctx, gatewayInitializeParams())
+		if err != nil {
+			return err
+		}
+		hc.initResult = result
+		return nil
+	}
+
+	_, err := hc.backend.SendRequest(ctx, "ping", struct{}{})
+	return err
+}
@@ -0,0 +1,310 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// probeBackend is a backend whose pings and handshakes fail on demand
+type probeBackend struct {
+	messageDispatcher
+	mu        sync.Mutex
+	healthy   bool
+	failing   bool
+	initCalls int
+	pingCalls int
+}
+
+func (b *probeBackend) Initialize(ctx context.Context, req interface{}) (*mcp.InitializeResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.initCalls++
+	if b.failing {
+		b.healthy = false
+		return nil, errTest
+	}
+	b.healthy = true
+	return &mcp.InitializeResult{Capabilities: &mcp.ServerCapabilities{Tools: &mcp.ToolCapabilities{}}}, nil
+}
+
+func (b *probeBackend) SendRequest(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pingCalls++
+	if b.failing {
+		b.healthy = false
+		return nil, errTest
+	}
+	// Like the real transports, a successful request marks the backend healthy
+	b.healthy = true
+	raw := json.RawMessage(`{}`)
+	return &raw, nil
+}
+
+func (b *probeBackend) GetInfo() BackendInfo {
+	return BackendInfo{Name: "probe-backend", Transport: "test", Group: "test-group"}
+}
+
+func (b *probeBackend) Close() error {
+	return nil
+}
+
+func (b *probeBackend) IsHealthy() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.healthy
+}
+
+func (b *probeBackend) SetHealthy(healthy bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.healthy = healthy
+}
+
+func (b *probeBackend) setFailing(failing bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failing = failing
+}
+
+func TestHealthChecker_MarksDownAfterThreshold(t *testing.T) {
+	backend := &probeBackend{healthy: true}
+	checker := newHealthChecker(backend, config.HealthCheckConfig{UnhealthyThreshold: 3}, nil)
+	ctx := context.Background()
+
+	backend.setFailing(true)
+	checker.check(ctx)
+	checker.check(ctx)
+	if !checker.up {
+		t.Fatal("Backend should stay up until the unhealthy threshold is reached")
+	}
+
+	// A success in between resets the failure count
+	backend.setFailing(false)
+	checker.check(ctx)
+	backend.setFailing(true)
+	checker.check(ctx)
+	checker.check(ctx)
+	if !checker.up {
+		t.Fatal("Failures must be consecutive to mark the backend down")
+	}
+
+	checker.check(ctx)
+	if checker.up {
+		t.Fatal("Backend should be down after 3 consecutive failures")
+	}
+	if backend.IsHealthy() {
+		t.Error("Backend should be marked unhealthy when it goes down")
+	}
+}
+
+func TestHealthChecker_RecoversAfterThreshold(t *testing.T) {
+	backend := &probeBackend{healthy: false}
+
+	var recovered []*mcp.InitializeResult
+	checker := newHealthChecker(backend, config.HealthCheckConfig{HealthyThreshold: 2}, func(ctx context.Context, b Backend, result *mcp.InitializeResult) {
+		recovered = append(recovered, result)
+	})
+	ctx := context.Background()
+
+	// The first successful check repeats the handshake
+	checker.check(ctx)
+	if backend.initCalls != 1 {
+		t.Errorf("Expected a down backend to be initialized, got %d initialize calls", backend.initCalls)
+	}
+	if checker.up || backend.IsHealthy() {
+		t.Fatal("Backend must be held down until the healthy threshold is reached")
+	}
+
+	// Later checks ping the freshly initialized backend
+	checker.check(ctx)
+	if backend.initCalls != 1 || backend.pingCalls != 1 {
+		t.Errorf("Expected 1 initialize and 1 ping, got %d and %d", backend.initCalls, backend.pingCalls)
+	}
+	if !checker.up || !backend.IsHealthy() {
+		t.Fatal("Backend should be up after 2 consecutive successes")
+	}
+	if len(recovered) != 1 || recovered[0] == nil || recovered[0].Capabilities.Tools == nil {
+		t.Fatalf("Expected recovery handler to receive the initialize result, got %v", recovered)
+	}
+
+	// Staying up does not trigger another recovery
+	checker.check(ctx)
+	if len(recovered) != 1 {
+		t.Errorf("Expected a single recovery, got %d", len(recovered))
+	}
+}
+
+func TestBackendManager_StopsHealthChecksOnClose(t *testing.T) {
+	backend := &probeBackend{healthy: true}
+	manager := NewBackendManager()
+	manager.AddBackend(backend)
+
+	manager.StartHealthChecks(map[string]config.HealthCheckConfig{
+		"probe-backend": {Interval: 5 * time.Millisecond},
+	}, nil)
+
+	pinged := waitFor(t, 5*time.Second, func() bool {
+		backend.mu.Lock()
+		defer backend.mu.Unlock()
+		return backend.pingCalls > 0
+	})
+	if !pinged {
+		t.Fatal("Health checker did not ping the backend")
+	}
+
+	if err := manager.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	backend.mu.Lock()
+	calls := backend.pingCalls
+	backend.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if backend.pingCalls != calls {
+		t.Error("Health checks should stop when the manager is closed")
+	}
+}
+
+func TestGateway_DiscoversBackendThatStartsLate(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "late", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "greet", Description: "Greets"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	handler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return server }, nil)
+
+	// The backend refuses connections until it is marked available
+	var available atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available.Load() {
+			http.Error(w, "starting", http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{
+		Groups: []config.Group{
+			{
+				Name: "test-group",
+				Backends: map[string]config.Backend{
+					"late-backend": {
+						Name:      "late-backend",
+						Transport: "http",
+						Endpoint:  ts.URL,
+						HealthCheck: config.HealthCheckConfig{
+							Interval:         20 * time.Millisecond,
+							HealthyThreshold: 1,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	if err := gateway.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+	if gateway.GetCapabilities().Tools {
+		t.Fatal("No tools should be available while the backend is down")
+	}
+
+	available.Store(true)
+
+	discovered := waitFor(t, 5*time.Second, func() bool {
+		_, exists := gateway.GetRoutingTable().FindToolBackend("greet")
+		return exists && gateway.GetCapabilities().Tools
+	})
+	if !discovered {
+		t.Fatal("Tools of the recovered backend were not discovered")
+	}
+
+	backend, _ := gateway.GetBackendManager().GetBackend("late-backend")
+	if !backend.IsHealthy() {
+		t.Error("Recovered backend should be healthy")
+	}
+
+	// The meta-tools are registered once the first tools appear
+	session := connectTestClient(t, gateway.GetServer(), nil)
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(tools.Tools) != 3 {
+		t.Errorf("Expected the 3 meta-tools, got %d tools", len(tools.Tools))
+	}
+}
+
+func TestHealthChecker_LeavesStdioRestartsToSupervisor(t *testing.T) {
+	dir := t.TempDir()
+	crashFile := filepath.Join(dir, "crash")
+	startsFile := filepath.Join(dir, "starts")
+	starts := func() int {
+		data, _ := os.ReadFile(startsFile)
+		return strings.Count(string(data), "\n")
+	}
+
+	cfg := helperBackendConfig("stdio-backend")
+	cfg.Env[stdioHelperCrashFileEnv] = crashFile
+	cfg.Env[stdioHelperStartsFileEnv] = startsFile
+	cfg.Restart = config.RestartConfig{MaxRestarts: 2, Backoff: 10 * time.Millisecond}
+	backend := NewStdioBackend(cfg, "test-group")
+	manager := NewBackendManager()
+	manager.AddBackend(backend)
+	defer func() { _ = manager.Close() }()
+
+	if _, err := backend.Initialize(context.Background(), testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	manager.StartHealthChecks(map[string]config.HealthCheckConfig{
+		"stdio-backend": {Interval: 10 * time.Millisecond, Timeout: time.Second},
+	}, nil)
+
+	// Every restarted process exits immediately
+	if err := os.WriteFile(crashFile, nil, 0644); err != nil {
+		t.Fatalf("Failed to write crash file: %v", err)
+	}
+	crashHelper(t, backend)
+
+	gaveUp := waitFor(t, 10*time.Second, func() bool {
+		backend.mu.RLock()
+		defer backend.mu.RUnlock()
+		return backend.gaveUp
+	})
+	if !gaveUp {
+		t.Fatal("Supervisor did not give up")
+	}
+
+	// Health checks keep running but do not start the process again
+	time.Sleep(300 * time.Millisecond)
+	if got := starts(); got != 3 {
+		t.Errorf("Expected the initial start and 2 restarts, got %d starts", got)
+	}
+	if backend.IsHealthy() {
+		t.Error("Backend should stay unhealthy after exhausting its restart budget")
+	}
+}
@@ -0,0 +1,186 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+)
+
+// jsonRPCMessage is a single JSON-RPC 2.0 message exchanged with a backend.
+// It covers requests, notifications and responses so that a reader can
+// decode any incoming message before deciding how to route it.
+type jsonRPCMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  *json.RawMessage `json:"params,omitempty"`
+	Result  *json.RawMessage `json:"result,omitempty"`
+	Error   *json.RawMessage `json:"error,omitempty"`
+}
+
+// isResponse reports whether the message is a response to one of our requests
+func (m *jsonRPCMessage) isResponse() bool {
+	return m.Method == "" && m.ID != nil
+}
+
+// idKey returns a normalized string form of the message id, so that ids
+// echoed back as numbers or strings can be correlated with pending requests
+func (m *jsonRPCMessage) idKey() string {
+	if m.ID == nil {
+		return ""
+	}
+	return normalizeID(*m.ID)
+}
+
+// normalizeID converts a raw JSON-RPC id into a comparable key
+func normalizeID(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return string(raw)
+}
+
+// resultOrError extracts the result of a response message
+func (m *jsonRPCMessage) resultOrError() (*json.RawMessage, error) {
+	if m.Error != nil {
+		return nil, fmt.Errorf("JSON-RPC error: %s", string(*m.Error))
+	}
+	if m.Result == nil {
+		return nil, fmt.Errorf("no result in response")
+	}
+	return m.Result, nil
+}
+
+// decodeJSONRPCMessages decodes a single message or a batch of messages
+func decodeJSONRPCMessages(data []byte) ([]jsonRPCMessage, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []jsonRPCMessage
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return nil, err
+		}
+		return batch, nil
+	}
+
+	var msg jsonRPCMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	return []jsonRPCMessage{msg}, nil
+}
+
+// newJSONRPCRequest builds a request with the given numeric id
+func newJSONRPCRequest(id int64, method string, params interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	}
+}
+
+// cancelledNotification encodes the notifications/cancelled message telling
+// a backend to stop working on one of our requests, which was abandoned
+// because ctxErr ended its context
+func cancelledNotification(id int64, ctxErr error) []byte {
+	reason := "request cancelled by client"
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		reason = "request timed out"
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "notifications/cancelled",
+		"params": map[string]interface{}{
+			"requestId": id,
+			"reason":    reason,
+		},
+	})
+	return data
+}
+
+// cancelRequest tells a backend to stop working on request id, which was
+// abandoned because ctxErr ended its context. The notification is sent with
+// send, which must not depend on the request's context. The initialize
+// request must not be cancelled.
+func cancelRequest(backendName string, send func([]byte) error, id int64, method string, ctxErr error) {
+	if method == "initialize" {
+		return
+	}
+	if err := send(cancelledNotification(id, ctxErr)); err != nil {
+		slog.Debug("Failed to cancel backend request", "backend", backendName, "jsonrpc_id", id, "error", err)
+	}
+}
+
+// pendingRequests correlates in-flight requests with their responses by id
+type pendingRequests struct {
+	mu      sync.Mutex
+	nextID  int64
+	waiters map[string]chan *jsonRPCMessage
+}
+
+// newPendingRequests creates an empty pending request table
+func newPendingRequests() *pendingRequests {
+	return &pendingRequests{
+		nextID:  1,
+		waiters: make(map[string]chan *jsonRPCMessage),
+	}
+}
+
+// register allocates a new request id and a channel that receives its response
+func (p *pendingRequests) register() (int64, chan *jsonRPCMessage) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := p.nextID
+	p.nextID++
+
+	ch := make(chan *jsonRPCMessage, 1)
+	p.waiters[strconv.FormatInt(id, 10)] = ch
+	return id, ch
+}
+
+// remove forgets a pending request, e.g. after its caller gave up waiting
+func (p *pendingRequests) remove(id int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.waiters, strconv.FormatInt(id, 10))
+}
+
+// deliver hands a response to the waiting caller. It returns false if no
+// request with the response's id is pending.
+func (p *pendingRequests) deliver(msg *jsonRPCMessage) bool {
+	p.mu.Lock()
+	ch, exists := p.waiters[msg.idKey()]
+	if exists {
+		delete(p.waiters, msg.idKey())
+	}
+	p.mu.Unlock()
+
+	if !exists {
+		return false
+	}
+	ch <- msg
+	return true
+}
+
+// failAll closes every pending channel so that waiting callers return
+func (p *pendingRequests) failAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, ch := range p.waiters {
+		close(ch)
+		delete(p.waiters, key)
+	}
+}
@@ -0,0 +1,375 @@
+package gateway
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// jwtClockSkew is the tolerance applied to the exp and nbf claims
+const jwtClockSkew = time.Minute
+
+// jwk is a JSON Web Key as found in a JWKS file
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// signingKey is a public key of the JWKS file and the algorithm it is
+// restricted to, if the file names one
+type signingKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// jwtVerifier validates JWTs against the public keys of a local JWKS file.
+// The file is read again when a token names a key it does not know and the
+// file changed, so keys can be rotated without restarting the gateway.
+type jwtVerifier struct {
+	config config.JWTConfig
+
+	mu      sync.Mutex
+	keys    map[string]signingKey // key id -> public key
+	modTime time.Time
+}
+
+// newJWTVerifier creates a verifier and loads its JWKS file
+func newJWTVerifier(cfg config.JWTConfig) (*jwtVerifier, error) {
+	v := &jwtVerifier{config: cfg}
+	if err := v.load(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// load reads the JWKS file
+func (v *jwtVerifier) load() error {
+	info, err := os.Stat(v.config.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	data, err := os.ReadFile(v.config.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return fmt.Errorf("failed to parse JWKS file %s: %w", v.config.JWKSFile, err)
+	}
+
+	keys := make(map[string]signingKey)
+	for i, key := range jwks.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		publicKey, err := key.publicKey()
+		if err != nil {
+			return fmt.Errorf("invalid key %d (%s) in JWKS file %s: %w", i, key.Kid, v.config.JWKSFile, err)
+		}
+		keys[key.Kid] = signingKey{alg: key.Alg, key: publicKey}
+	}
+
+	v.keys = keys
+	v.modTime = info.ModTime()
+	return nil
+}
+
+// reloadIfChanged reads the JWKS file again if it was modified since it was
+// last loaded. The caller must hold v.mu.
+func (v *jwtVerifier) reloadIfChanged() {
+	info, err := os.Stat(v.config.JWKSFile)
+	if err != nil || info.ModTime().Equal(v.modTime) {
+		return
+	}
+	if err := v.load(); err != nil {
+		slog.Error("Failed to reload JWKS file", "error", err)
+	}
+}
+
+// candidateKeys returns the keys a token may have been signed with: the key
+// named by kid, or every key if the token does not name one
+func (v *jwtVerifier) candidateKeys(kid string) []signingKey {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if kid != "" {
+		if _, exists := v.keys[kid]; !exists {
+			v.reloadIfChanged()
+		}
+		if key, exists := v.keys[kid]; exists {
+			return []signingKey{key}
+		}
+		return nil
+	}
+
+	keys := make([]signingKey, 0, len(v.keys))
+	for _, key := range v.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// verify checks the signature and the time, issuer and audience claims of a
+// compact-serialized JWT and returns its claims
+func (v *jwtVerifier) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+
+	keys := v.candidateKeys(header.Kid)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("unknown signing key %q", header.Kid)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range keys {
+		if key.alg != "" && key.alg != header.Alg {
+			if len(keys) == 1 {
+				return nil, fmt.Errorf("key %q is not used with algorithm %s", header.Kid, header.Alg)
+			}
+			continue
+		}
+		if err := verifyJWTSignature(header.Alg, key.key, signed, signature); err == nil {
+			verified = true
+			break
+		} else if len(keys) == 1 {
+			return nil, err
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("invalid token signature")
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+
+	if err := v.checkClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// checkClaims validates the registered claims of a token
+func (v *jwtVerifier) checkClaims(claims map[string]interface{}) error {
+	now := time.Now()
+
+	exp := claimTime(claims, "exp")
+	if exp.IsZero() {
+		return fmt.Errorf("token has no expiration")
+	}
+	if now.After(exp.Add(jwtClockSkew)) {
+		return fmt.Errorf("token expired")
+	}
+
+	if nbf := claimTime(claims, "nbf"); !nbf.IsZero() && now.Add(jwtClockSkew).Before(nbf) {
+		return fmt.Errorf("token not valid yet")
+	}
+
+	if v.config.Issuer != "" {
+		if issuer, _ := claims["iss"].(string); issuer != v.config.Issuer {
+			return fmt.Errorf("unexpected token issuer %q", issuer)
+		}
+	}
+
+	if v.config.Audience != "" && !claimContains(claims, "aud", v.config.Audience) {
+		return fmt.Errorf("token is not intended for audience %q", v.config.Audience)
+	}
+
+	return nil
+}
+
+// decodeJWTPart decodes a base64url-encoded JSON segment of a token
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// claimTime returns a NumericDate claim, or the zero time if it is absent
+func claimTime(claims map[string]interface{}, name string) time.Time {
+	seconds, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(int64(seconds), 0)
+}
+
+// claimContains reports whether a claim that is a string or an array of
+// strings contains value
+func claimContains(claims map[string]interface{}, name, value string) bool {
+	switch claim := claims[name].(type) {
+	case string:
+		return claim == value
+	case []interface{}:
+		for _, item := range claim {
+			if item == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verifyJWTSignature checks a signature made with one of the asymmetric JWS
+// algorithms. Symmetric algorithms and "none" are rejected.
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	case "EdDSA":
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(edKey, signed, signature) {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+
+	hasher := hash.New()
+	hasher.Write(signed)
+	digest := hasher.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key does not match signing algorithm %s", alg)
+		}
+		var err error
+		if alg[:2] == "RS" {
+			err = rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+		} else {
+			err = rsa.VerifyPSS(rsaKey, hash, digest, signature, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid token signature")
+		}
+	case "ES":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key does not match signing algorithm %s", alg)
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("invalid token signature")
+		}
+	}
+	return nil
+}
+
+// publicKey converts the JWK to an RSA, ECDSA or Ed25519 public key
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, fmt.Errorf("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeJWKInt decodes a base64url-encoded big-endian integer
+func decodeJWKInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
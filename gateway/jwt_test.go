@@ -0,0 +1,260 @@
+package gateway
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// testSigner signs JWTs with a generated key and describes it as a JWK
+type testSigner struct {
+	kid string
+	alg string
+	key crypto.Signer
+}
+
+func newRSATestSigner(t *testing.T, kid string) *testSigner {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	return &testSigner{kid: kid, alg: "RS256", key: key}
+}
+
+func newECTestSigner(t *testing.T, kid string) *testSigner {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+	return &testSigner{kid: kid, alg: "ES256", key: key}
+}
+
+func newEdDSATestSigner(t *testing.T, kid string) *testSigner {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	return &testSigner{kid: kid, alg: "EdDSA", key: key}
+}
+
+// jwk returns the public key of the signer as a JWK
+func (s *testSigner) jwk() map[string]string {
+	encode := base64.RawURLEncoding.EncodeToString
+	switch key := s.key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{"kty": "RSA", "kid": s.kid, "n": encode(key.N.Bytes()), "e": encode(big.NewInt(int64(key.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		return map[string]string{"kty": "EC", "kid": s.kid, "crv": "P-256", "x": encode(key.X.FillBytes(make([]byte, 32))), "y": encode(key.Y.FillBytes(make([]byte, 32)))}
+	case ed25519.PublicKey:
+		return map[string]string{"kty": "OKP", "kid": s.kid, "crv": "Ed25519", "x": encode(key)}
+	}
+	return nil
+}
+
+// sign creates a token with the given claims
+func (s *testSigner) sign(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	return s.signWithHeader(t, map[string]interface{}{"alg": s.alg, "kid": s.kid, "typ": "JWT"}, claims)
+}
+
+func (s *testSigner) signWithHeader(t *testing.T, header, claims map[string]interface{}) string {
+	t.Helper()
+	headerJSON, _ := json.Marshal(header)
+	claimsJSON, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+
+	var signature []byte
+	var err error
+	switch key := s.key.(type) {
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var r, sig *big.Int
+		r, sig, err = ecdsa.Sign(rand.Reader, key, digest[:])
+		if err == nil {
+			signature = append(r.FillBytes(make([]byte, 32)), sig.FillBytes(make([]byte, 32))...)
+		}
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, []byte(signed))
+	}
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// writeJWKS writes the public keys of signers to a JWKS file
+func writeJWKS(t *testing.T, path string, signers ...*testSigner) {
+	t.Helper()
+	keys := make([]map[string]string, 0, len(signers))
+	for _, signer := range signers {
+		keys = append(keys, signer.jwk())
+	}
+	data, _ := json.Marshal(map[string]interface{}{"keys": keys})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write JWKS file: %v", err)
+	}
+}
+
+// validClaims returns claims for a token that expires in an hour
+func validClaims(subject string) map[string]interface{} {
+	return map[string]interface{}{
+		"sub": subject,
+		"iss": "https://auth.example.com",
+		"aud": []string{"mcp-gateway"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTVerifier_Algorithms(t *testing.T) {
+	signers := []*testSigner{
+		newRSATestSigner(t, "rsa-key"),
+		newECTestSigner(t, "ec-key"),
+		newEdDSATestSigner(t, "ed-key"),
+	}
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksFile, signers...)
+
+	verifier, err := newJWTVerifier(config.JWTConfig{JWKSFile: jwksFile})
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	for _, signer := range signers {
+		claims, err := verifier.verify(signer.sign(t, validClaims("alice")))
+		if err != nil {
+			t.Errorf("%s token rejected: %v", signer.alg, err)
+			continue
+		}
+		if claims["sub"] != "alice" {
+			t.Errorf("%s token: expected subject alice, got %v", signer.alg, claims["sub"])
+		}
+	}
+}
+
+func TestJWTVerifier_RejectsInvalidTokens(t *testing.T) {
+	signer := newRSATestSigner(t, "rsa-key")
+	other := newRSATestSigner(t, "rsa-key")
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksFile, signer)
+
+	verifier, err := newJWTVerifier(config.JWTConfig{
+		JWKSFile: jwksFile,
+		Issuer:   "https://auth.example.com",
+		Audience: "mcp-gateway",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	expired := validClaims("alice")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	notYetValid := validClaims("alice")
+	notYetValid["nbf"] = time.Now().Add(time.Hour).Unix()
+
+	noExpiration := validClaims("alice")
+	delete(noExpiration, "exp")
+
+	wrongIssuer := validClaims("alice")
+	wrongIssuer["iss"] = "https://evil.example.com"
+
+	wrongAudience := validClaims("alice")
+	wrongAudience["aud"] = "another-service"
+
+	valid := signer.sign(t, validClaims("alice"))
+	parts := strings.Split(valid, ".")
+	unsignedHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"rsa-key"}`))
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"expired", signer.sign(t, expired), "expired"},
+		{"not yet valid", signer.sign(t, notYetValid), "not valid yet"},
+		{"no expiration", signer.sign(t, noExpiration), "no expiration"},
+		{"wrong issuer", signer.sign(t, wrongIssuer), "issuer"},
+		{"wrong audience", signer.sign(t, wrongAudience), "audience"},
+		{"signed by another key", other.sign(t, validClaims("alice")), "signature"},
+		{"unknown key id", signer.signWithHeader(t, map[string]interface{}{"alg": "RS256", "kid": "missing"}, validClaims("alice")), "unknown signing key"},
+		{"alg none", unsignedHeader + "." + parts[1] + ".", "unsupported signing algorithm"},
+		{"tampered claims", parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin","exp":9999999999}`)) + "." + parts[2], "signature"},
+		{"malformed", "not-a-jwt", "malformed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.verify(tt.token)
+			if err == nil {
+				t.Fatal("Expected token to be rejected")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestJWTVerifier_ReloadsRotatedKeys(t *testing.T) {
+	oldSigner := newRSATestSigner(t, "2024-key")
+	newSigner := newECTestSigner(t, "2025-key")
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksFile, oldSigner)
+
+	verifier, err := newJWTVerifier(config.JWTConfig{JWKSFile: jwksFile})
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	token := newSigner.sign(t, validClaims("alice"))
+	if _, err := verifier.verify(token); err == nil {
+		t.Fatal("Token signed with a key missing from the JWKS file should be rejected")
+	}
+
+	// Rotate the keys and make sure the modification time differs
+	writeJWKS(t, jwksFile, oldSigner, newSigner)
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(jwksFile, future, future); err != nil {
+		t.Fatalf("Failed to update JWKS file time: %v", err)
+	}
+
+	if _, err := verifier.verify(token); err != nil {
+		t.Errorf("Token signed with the rotated key should be accepted: %v", err)
+	}
+}
+
+func TestJWTVerifier_RejectsInvalidJWKS(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := newJWTVerifier(config.JWTConfig{JWKSFile: filepath.Join(dir, "missing.json")}); err == nil {
+		t.Error("Expected an error for a missing JWKS file")
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"keys":[{"kty":"EC","kid":"bad","crv":"P-256","x":"AQ","y":"AQ"}]}`), 0644); err != nil {
+		t.Fatalf("Failed to write JWKS file: %v", err)
+	}
+	if _, err := newJWTVerifier(config.JWTConfig{JWKSFile: invalid}); err == nil {
+		t.Error("Expected an error for a key that is not on its curve")
+	}
+}
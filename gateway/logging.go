@@ -0,0 +1,88 @@
+package gateway
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// NewLogger creates the gateway's logger from the logging configuration.
+// Records are written as text or JSON to the configured file, or to stderr.
+// When logging is disabled only errors are written. The returned function
+// closes the log file.
+func NewLogger(cfg config.LoggingConfig) (*slog.Logger, func() error, error) {
+	level := slog.LevelInfo
+	if cfg.Level != "" {
+		if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+			return nil, nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+		}
+	}
+	if !cfg.Enabled {
+		level = slog.LevelError
+	}
+
+	var out io.Writer = os.Stderr
+	closeOutput := func() error { return nil }
+	switch cfg.Output {
+	case "", "stderr":
+	case "stdout":
+		out = os.Stdout
+	default:
+		file, err := os.OpenFile(cfg.Output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		out = file
+		closeOutput = file.Close
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if strings.EqualFold(cfg.Format, config.LogFormatJSON) {
+		handler = slog.NewJSONHandler(out, opts)
+	} else {
+		handler = slog.NewTextHandler(out, opts)
+	}
+	return slog.New(handler), closeOutput, nil
+}
+
+// sessionID returns the id of a client session, or an empty string for
+// sessions without one such as in-memory connections
+func sessionID(session *mcp.ServerSession) string {
+	if session == nil {
+		return ""
+	}
+	return session.ID()
+}
+
+// requestSessionID returns the id of the session a request was sent on
+func requestSessionID(request *mcp.CallToolRequest) string {
+	if request == nil {
+		return ""
+	}
+	return sessionID(request.Session)
+}
+
+// requestIDHeader is the HTTP header carrying a client-chosen request id
+const requestIDHeader = "X-Request-Id"
+
+// requestID returns the id under which a client request is logged: the one
+// the client sent in the X-Request-Id header, or else a new random id
+func requestID(request *mcp.CallToolRequest) string {
+	if request != nil && request.Extra != nil && request.Extra.Header != nil {
+		if id := request.Extra.Header.Get(requestIDHeader); id != "" {
+			return id
+		}
+	}
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,126 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+func TestNewLogger_JSONFile(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "gateway.log")
+	logger, closeLog, err := NewLogger(config.LoggingConfig{
+		Enabled: true,
+		Level:   "warn",
+		Format:  config.LogFormatJSON,
+		Output:  logFile,
+	})
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+
+	logger.Info("dropped", "backend", "fs-a")
+	logger.Warn("kept", "backend", "fs-a")
+	if err := closeLog(); err != nil {
+		t.Fatalf("Failed to close log file: %v", err)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the warning to be logged, got %q", data)
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Expected a JSON record, got %q", lines[0])
+	}
+	if record["msg"] != "kept" || record["level"] != "WARN" || record["backend"] != "fs-a" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+}
+
+func TestNewLogger_DisabledLogsErrorsOnly(t *testing.T) {
+	logger, _, err := NewLogger(config.LoggingConfig{Enabled: false, Level: "debug"})
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if logger.Enabled(ctx, slog.LevelWarn) || !logger.Enabled(ctx, slog.LevelError) {
+		t.Error("Expected a disabled logger to write errors only")
+	}
+}
+
+func TestNewLogger_InvalidLevel(t *testing.T) {
+	if _, _, err := NewLogger(config.LoggingConfig{Enabled: true, Level: "verbose"}); err == nil {
+		t.Error("Expected an invalid level to be rejected")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	request := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{}}}
+	request.Extra.Header.Set(requestIDHeader, "client-chosen")
+	if id := requestID(request); id != "client-chosen" {
+		t.Errorf("Expected the client's request id, got %s", id)
+	}
+
+	if first, second := requestID(nil), requestID(nil); first == "" || first == second {
+		t.Errorf("Expected unique generated ids, got %q and %q", first, second)
+	}
+}
+
+func TestMetaToolHandler_LogsToolCalls(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer slog.SetDefault(previous)
+
+	backend := newHelperStdioBackend(t, "fs-a")
+	if _, err := backend.Initialize(context.Background(), testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	manager := NewBackendManager()
+	manager.AddBackend(backend)
+	rt := NewRoutingTable()
+	rt.setBackendTools(backend.GetInfo(), config.NamespaceNone, []string{"echo"}, nil)
+	handler := NewMetaToolHandler(manager, rt)
+
+	if _, _, err := handler.HandleCallTool(context.Background(), &mcp.CallToolRequest{}, CallToolParams{
+		ToolName:  "echo",
+		Arguments: map[string]interface{}{"value": "logged"},
+	}); err != nil {
+		t.Fatalf("HandleCallTool failed: %v", err)
+	}
+
+	var summary map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err == nil && record["msg"] == "Tool call completed" {
+			summary = record
+		}
+	}
+	if summary == nil {
+		t.Fatalf("Expected a tool call summary, got %s", buf.String())
+	}
+	for key, expected := range map[string]interface{}{"tool": "echo", "backend": "fs-a", "group": "test-group", "is_error": false} {
+		if summary[key] != expected {
+			t.Errorf("Expected %s=%v in summary, got %v", key, expected, summary[key])
+		}
+	}
+	if id, _ := summary["request_id"].(string); id == "" {
+		t.Error("Expected the summary to carry a request id")
+	}
+}
@@ -3,7 +3,12 @@ package gateway
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -12,6 +17,10 @@ import (
 type MetaToolHandler struct {
 	backendManager *BackendManager
 	routingTable   *RoutingTable
+	notifications  *NotificationRouter
+	authorizer     *Authorizer    // nil if clients are not authorized per group
+	cache          *responseCache // nil if caching is disabled
+	pageSize       int            // tools per list_tools page, zero for the SDK default
 }
 
 // NewMetaToolHandler creates a new meta-tool handler
@@ -23,17 +32,19 @@ func NewMetaToolHandler(backendManager *BackendManager, routingTable *RoutingTab
 }
 
 // ListToolsParams represents parameters for list_tools meta-tool
-type ListToolsParams struct{}
+type ListToolsParams struct {
+	Cursor string `json:"cursor,omitempty" jsonschema:"The cursor returned by the previous list_tools call, to get the next page of tools"`
+}
 
 // DescribeToolParams represents parameters for describe_tool meta-tool
 type DescribeToolParams struct {
-	ToolName string `json:"tool_name" jsonschema:"required,description=The name of the tool to describe"`
+	ToolName string `json:"tool_name" jsonschema:"The name of the tool to describe, as returned by list_tools or qualified as backend.tool"`
 }
 
 // CallToolParams represents parameters for call_tool meta-tool
 type CallToolParams struct {
-	ToolName  string                 `json:"tool_name" jsonschema:"required,description=The name of the tool to call"`
-	Arguments map[string]interface{} `json:"arguments" jsonschema:"required,description=The arguments to pass to the tool"`
+	ToolName  string                 `json:"tool_name" jsonschema:"The name of the tool to call, as returned by list_tools or qualified as backend.tool"`
+	Arguments map[string]interface{} `json:"arguments" jsonschema:"The arguments to pass to the tool"`
 }
 
 // GetMetaTools returns the three meta-tools definitions
@@ -62,10 +73,36 @@ func (mth *MetaToolHandler) HandleListTools(ctx context.Context, request *mcp.Ca
 	// Get all available tools from routing table
 	tools := mth.routingTable.GetAllTools()
 
+	// Hide the tools of unhealthy backends and of groups the client may not
+	// access, like resources and prompts
+	visible := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		if route, exists := mth.routingTable.ResolveTool(tool); exists && backendListed(mth.backendManager, mth.authorizer, request, route.Backend) {
+			visible = append(visible, tool)
+		}
+	}
+	tools = visible
+
+	tools, nextCursor, err := pageNames(tools, params.Cursor, mth.pageSize)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: err.Error(),
+				},
+			},
+			IsError: true,
+		}, nil, err
+	}
+
+	text := fmt.Sprintf("Available tools: %v", tools)
+	if nextCursor != "" {
+		text += fmt.Sprintf("\nMore tools are available; call list_tools with cursor %q", nextCursor)
+	}
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("Available tools: %v", tools),
+				Text: text,
 			},
 		},
 	}, tools, nil
@@ -74,7 +111,8 @@ func (mth *MetaToolHandler) HandleListTools(ctx context.Context, request *mcp.Ca
 // HandleDescribeTool implements the describe_tool meta-tool
 func (mth *MetaToolHandler) HandleDescribeTool(ctx context.Context, request *mcp.CallToolRequest, params DescribeToolParams) (*mcp.CallToolResult, interface{}, error) {
 	// Find backend that provides this tool
-	backendName, exists := mth.routingTable.FindToolBackend(params.ToolName)
+	route, exists := mth.routingTable.ResolveTool(params.ToolName)
+	backendName := route.Backend
 	if !exists {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -86,6 +124,17 @@ func (mth *MetaToolHandler) HandleDescribeTool(ctx context.Context, request *mcp
 		}, nil, fmt.Errorf("tool '%s' not found", params.ToolName)
 	}
 
+	if err := mth.authorize(request, params.ToolName, backendName); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: err.Error(),
+				},
+			},
+			IsError: true,
+		}, nil, err
+	}
+
 	// Get backend
 	backend, exists := mth.backendManager.GetBackend(backendName)
 	if !exists {
@@ -100,7 +149,7 @@ func (mth *MetaToolHandler) HandleDescribeTool(ctx context.Context, request *mcp
 	}
 
 	// Get tools list from backend to find the specific tool description
-	response, err := backend.SendRequest(ctx, "tools/list", struct{}{})
+	tools, err := mth.listBackendTools(ctx, backend)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -112,24 +161,16 @@ func (mth *MetaToolHandler) HandleDescribeTool(ctx context.Context, request *mcp
 		}, nil, fmt.Errorf("failed to get tools from backend: %w", err)
 	}
 
-	var toolsResponse struct {
-		Tools []mcp.Tool `json:"tools"`
-	}
-
-	if err := json.Unmarshal(*response, &toolsResponse); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: fmt.Sprintf("Failed to parse tools response: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil, fmt.Errorf("failed to parse tools response: %w", err)
-	}
-
 	// Find the specific tool
-	for _, tool := range toolsResponse.Tools {
-		if tool.Name == params.ToolName {
+	for _, tool := range tools {
+		if tool.Name == route.Tool {
+			// Describe the tool under the name clients call it by
+			tool.Name = params.ToolName
+			if route.Description != "" {
+				tool.Description = route.Description
+			}
+			tool.InputSchema = withSchemaDefaults(tool.InputSchema, route.Defaults)
+
 			// Return the tool description
 			toolData, err := json.Marshal(tool)
 			if err != nil {
@@ -163,10 +204,32 @@ func (mth *MetaToolHandler) HandleDescribeTool(ctx context.Context, request *mcp
 	}, nil, fmt.Errorf("tool '%s' not found in backend '%s'", params.ToolName, backendName)
 }
 
-// HandleCallTool implements the call_tool meta-tool
+// HandleCallTool implements the call_tool meta-tool and logs a summary of
+// each call
 func (mth *MetaToolHandler) HandleCallTool(ctx context.Context, request *mcp.CallToolRequest, params CallToolParams) (*mcp.CallToolResult, interface{}, error) {
+	start := time.Now()
+	logger := slog.With("tool", params.ToolName, "session_id", requestSessionID(request), "request_id", requestID(request))
+	if route, exists := mth.routingTable.ResolveTool(params.ToolName); exists {
+		logger = logger.With("backend", route.Backend, "group", mth.backendGroup(route.Backend))
+	}
+	logger.Debug("Tool call", "arguments", slices.Sorted(maps.Keys(params.Arguments)))
+
+	result, data, err := mth.callTool(ctx, request, params, logger)
+
+	duration := time.Since(start)
+	if err != nil {
+		logger.Warn("Tool call failed", "duration_ms", duration.Milliseconds(), "error", err)
+	} else {
+		logger.Info("Tool call completed", "duration_ms", duration.Milliseconds(), "is_error", result.IsError, "content_items", len(result.Content))
+	}
+	return result, data, err
+}
+
+// callTool routes a call_tool request to the backend providing the tool
+func (mth *MetaToolHandler) callTool(ctx context.Context, request *mcp.CallToolRequest, params CallToolParams, logger *slog.Logger) (*mcp.CallToolResult, interface{}, error) {
 	// Find backend that provides this tool
-	backendName, exists := mth.routingTable.FindToolBackend(params.ToolName)
+	route, exists := mth.routingTable.ResolveTool(params.ToolName)
+	backendName := route.Backend
 	if !exists {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -178,6 +241,17 @@ func (mth *MetaToolHandler) HandleCallTool(ctx context.Context, request *mcp.Cal
 		}, nil, fmt.Errorf("tool '%s' not found", params.ToolName)
 	}
 
+	if err := mth.authorize(request, params.ToolName, backendName); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: err.Error(),
+				},
+			},
+			IsError: true,
+		}, nil, err
+	}
+
 	// Get backend
 	backend, exists := mth.backendManager.GetBackend(backendName)
 	if !exists {
@@ -207,13 +281,63 @@ func (mth *MetaToolHandler) HandleCallTool(ctx context.Context, request *mcp.Cal
 	toolCallParams := struct {
 		Name      string                 `json:"name"`
 		Arguments map[string]interface{} `json:"arguments"`
+		Meta      map[string]interface{} `json:"_meta,omitempty"`
 	}{
-		Name:      params.ToolName,
-		Arguments: params.Arguments,
+		Name:      route.Tool,
+		Arguments: withDefaultArguments(params.Arguments, route.Defaults),
+	}
+
+	// Answer calls of idempotent tools from the cache
+	var key cacheKey
+	cacheable := mth.cache != nil && route.Cache
+	if cacheable {
+		var err error
+		if key, err = toolCallKey(backendName, route.Tool, toolCallParams.Arguments); err != nil {
+			cacheable = false
+		} else if cached, hit := mth.cache.get(key); hit {
+			var toolResult mcp.CallToolResult
+			if err := json.Unmarshal(cached, &toolResult); err == nil {
+				logger.Debug("Serving tool call from cache")
+				return &toolResult, nil, nil
+			}
+		}
+	}
+
+	// Let backend-initiated messages reach the calling session
+	if mth.notifications != nil {
+		defer mth.notifications.TrackCall(backendName, request.Session)()
+
+		if request.Params != nil && request.Params.GetProgressToken() != nil {
+			token, release := mth.notifications.RegisterProgressToken(request.Session, request.Params.GetProgressToken())
+			defer release()
+			toolCallParams.Meta = map[string]interface{}{"progressToken": token}
+		}
+	}
+
+	// Bound the call by the tool's timeout, or else the backend's
+	timeout := route.Timeout
+	if provider, ok := backend.(TimeoutProvider); ok && timeout == 0 {
+		timeout = provider.RequestTimeout()
+	}
+	callCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
 	// Send the tool call to the backend
-	response, err := backend.SendRequest(ctx, "tools/call", toolCallParams)
+	response, err := backend.SendRequest(callCtx, "tools/call", toolCallParams)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Tool '%s' timed out after %s", params.ToolName, timeout),
+				},
+			},
+			IsError: true,
+		}, nil, fmt.Errorf("tool '%s' timed out after %s: %w", params.ToolName, timeout, err)
+	}
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -238,10 +362,124 @@ func (mth *MetaToolHandler) HandleCallTool(ctx context.Context, request *mcp.Cal
 		}, nil, fmt.Errorf("failed to parse tool response: %w", err)
 	}
 
+	if cacheable && !toolResult.IsError {
+		mth.cache.set(key, *response)
+	}
+
 	// Return the result from backend
 	return &toolResult, nil, nil
 }
 
+// listBackendTools lists every page of a backend's tools, answering from
+// the cache if possible
+func (mth *MetaToolHandler) listBackendTools(ctx context.Context, backend Backend) ([]mcp.Tool, error) {
+	if mth.cache == nil {
+		return listAll[mcp.Tool](ctx, backend, "tools/list", "tools")
+	}
+
+	key := toolsListKey(backend.GetInfo().Name)
+	if cached, hit := mth.cache.get(key); hit {
+		var tools []mcp.Tool
+		if err := json.Unmarshal(cached, &tools); err == nil {
+			return tools, nil
+		}
+	}
+
+	tools, err := listAll[mcp.Tool](ctx, backend, "tools/list", "tools")
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(tools); err == nil {
+		mth.cache.set(key, data)
+	}
+	return tools, nil
+}
+
+// withDefaultArguments returns arguments with defaults added for the
+// arguments the client left out
+func withDefaultArguments(arguments, defaults map[string]interface{}) map[string]interface{} {
+	if len(defaults) == 0 {
+		return arguments
+	}
+
+	merged := maps.Clone(defaults)
+	maps.Copy(merged, arguments)
+	return merged
+}
+
+// withSchemaDefaults records default argument values in a tool's input
+// schema, so that clients know they may leave those arguments out
+func withSchemaDefaults(schema interface{}, defaults map[string]interface{}) interface{} {
+	object, ok := schema.(map[string]interface{})
+	if !ok || len(defaults) == 0 {
+		return schema
+	}
+
+	object = maps.Clone(object)
+	properties, _ := object["properties"].(map[string]interface{})
+	properties = maps.Clone(properties)
+	if properties == nil {
+		properties = make(map[string]interface{})
+	}
+	for argument, value := range defaults {
+		property, _ := properties[argument].(map[string]interface{})
+		property = maps.Clone(property)
+		if property == nil {
+			property = make(map[string]interface{})
+		}
+		property["default"] = value
+		properties[argument] = property
+	}
+	object["properties"] = properties
+
+	// Arguments with a default are no longer required
+	if required, ok := object["required"].([]interface{}); ok {
+		object["required"] = slices.DeleteFunc(slices.Clone(required), func(name interface{}) bool {
+			argument, _ := name.(string)
+			_, hasDefault := defaults[argument]
+			return hasDefault
+		})
+	}
+	return object
+}
+
+// authorize returns an error wrapping ErrPermissionDenied if the calling
+// client may not access the group of the backend providing a tool
+func (mth *MetaToolHandler) authorize(request *mcp.CallToolRequest, toolName, backendName string) error {
+	if mth.authorizer == nil {
+		return nil
+	}
+
+	return mth.authorizer.Authorize(requestIdentity(request), mth.backendGroup(backendName), fmt.Sprintf("use tool '%s'", toolName), requestSessionID(request))
+}
+
+// backendGroup returns the group a backend belongs to
+func (mth *MetaToolHandler) backendGroup(backendName string) string {
+	backend, exists := mth.backendManager.GetBackend(backendName)
+	if !exists {
+		return ""
+	}
+	return backend.GetInfo().Group
+}
+
+// requestIdentity returns the authenticated identity of the client that sent
+// a request. The SDK only passes it on for the Streamable HTTP transport.
+func requestIdentity(request *mcp.CallToolRequest) *Identity {
+	if request == nil {
+		return nil
+	}
+	return extraIdentity(request.Extra)
+}
+
+// extraIdentity returns the authenticated identity in a request's extra
+// information
+func extraIdentity(extra *mcp.RequestExtra) *Identity {
+	if extra == nil {
+		return nil
+	}
+	return identityFromTokenInfo(extra.TokenInfo)
+}
+
 // ValidateMetaToolCall checks if a tool call is for a meta-tool and validates it
 func (mth *MetaToolHandler) ValidateMetaToolCall(toolName string) (bool, error) {
 	switch toolName {
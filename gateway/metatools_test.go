@@ -3,9 +3,15 @@ package gateway
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
 )
 
 func TestMetaToolHandler_GetMetaTools(t *testing.T) {
@@ -39,11 +45,25 @@ func TestMetaToolHandler_GetMetaTools(t *testing.T) {
 	}
 }
 
+// namedProbeBackend is a probe backend with a name of its own
+type namedProbeBackend struct {
+	probeBackend
+	name string
+}
+
+func (b *namedProbeBackend) GetInfo() BackendInfo {
+	return BackendInfo{Name: b.name, Transport: "test", Group: "test-group"}
+}
+
 func TestMetaToolHandler_HandleListTools(t *testing.T) {
 	manager := NewBackendManager()
+	manager.AddBackend(&namedProbeBackend{probeBackend: probeBackend{healthy: true}, name: "backend1"})
+	manager.AddBackend(&namedProbeBackend{probeBackend: probeBackend{healthy: true}, name: "backend2"})
+	manager.AddBackend(&namedProbeBackend{probeBackend: probeBackend{healthy: false}, name: "backend3"})
 	rt := NewRoutingTable()
 	rt.ToolsMap["tool1"] = "backend1"
 	rt.ToolsMap["tool2"] = "backend2"
+	rt.ToolsMap["tool3"] = "backend3" // Hidden while its backend is down
 
 	handler := NewMetaToolHandler(manager, rt)
 
@@ -220,3 +240,272 @@ func TestCallToolParams_Structure(t *testing.T) {
 		t.Errorf("Expected arg1 to be value1, got %v", unmarshaled.Arguments["arg1"])
 	}
 }
+
+func TestMetaToolHandler_QualifiedToolNames(t *testing.T) {
+	backend := newHelperStdioBackend(t, "fs-a")
+	if _, err := backend.Initialize(context.Background(), testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	manager := NewBackendManager()
+	manager.AddBackend(backend)
+	rt := NewRoutingTable()
+	rt.setBackendTools(backend.GetInfo(), config.NamespaceBackend, []string{"echo"}, nil)
+	handler := NewMetaToolHandler(manager, rt)
+
+	ctx := context.Background()
+
+	// The backend receives its own tool name
+	result, _, err := handler.HandleCallTool(ctx, &mcp.CallToolRequest{}, CallToolParams{
+		ToolName:  "fs-a.echo",
+		Arguments: map[string]interface{}{"value": "namespaced"},
+	})
+	if err != nil {
+		t.Fatalf("HandleCallTool failed: %v", err)
+	}
+	if text, ok := result.Content[0].(*mcp.TextContent); !ok || text.Text != "namespaced" {
+		t.Errorf("Unexpected result: %v", result.Content[0])
+	}
+
+	// The tool is described under the name clients call it by
+	_, data, err := handler.HandleDescribeTool(ctx, &mcp.CallToolRequest{}, DescribeToolParams{ToolName: "fs-a.echo"})
+	if err != nil {
+		t.Fatalf("HandleDescribeTool failed: %v", err)
+	}
+	if tool, ok := data.(mcp.Tool); !ok || tool.Name != "fs-a.echo" {
+		t.Errorf("Expected tool described as fs-a.echo, got %v", data)
+	}
+}
+
+func TestMetaToolHandler_ToolOverrides(t *testing.T) {
+	backend := newHelperStdioBackend(t, "fs-a")
+	if _, err := backend.Initialize(context.Background(), testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	manager := NewBackendManager()
+	manager.AddBackend(backend)
+	rt := NewRoutingTable()
+	rt.setBackendTools(backend.GetInfo(), config.NamespaceNone, []string{"echo"}, map[string]config.ToolOverride{
+		"echo": {
+			Tool:        "echo",
+			Name:        "say",
+			Aliases:     []string{"repeat"},
+			Description: "Says the value back",
+			Defaults:    []config.ArgumentDefault{{Argument: "value", Value: "hello"}},
+		},
+	})
+	handler := NewMetaToolHandler(manager, rt)
+
+	ctx := context.Background()
+	tests := []struct {
+		toolName  string
+		arguments map[string]interface{}
+		expected  string
+	}{
+		{"say", map[string]interface{}{}, "hello"},
+		{"say", map[string]interface{}{"value": "explicit"}, "explicit"},
+		{"repeat", map[string]interface{}{}, "hello"},
+		{"fs-a.echo", nil, "hello"},
+	}
+	for _, tt := range tests {
+		result, _, err := handler.HandleCallTool(ctx, &mcp.CallToolRequest{}, CallToolParams{ToolName: tt.toolName, Arguments: tt.arguments})
+		if err != nil {
+			t.Fatalf("HandleCallTool(%s) failed: %v", tt.toolName, err)
+		}
+		if text, ok := result.Content[0].(*mcp.TextContent); !ok || text.Text != tt.expected {
+			t.Errorf("Expected %s with %v to return %q, got %v", tt.toolName, tt.arguments, tt.expected, result.Content[0])
+		}
+	}
+
+	// The backend's name is no longer exposed on its own
+	if _, _, err := handler.HandleCallTool(ctx, &mcp.CallToolRequest{}, CallToolParams{ToolName: "echo"}); err == nil {
+		t.Error("Expected the original tool name to be unknown")
+	}
+
+	_, data, err := handler.HandleDescribeTool(ctx, &mcp.CallToolRequest{}, DescribeToolParams{ToolName: "say"})
+	if err != nil {
+		t.Fatalf("HandleDescribeTool failed: %v", err)
+	}
+	if tool, ok := data.(mcp.Tool); !ok || tool.Name != "say" || tool.Description != "Says the value back" {
+		t.Errorf("Expected the overridden description, got %v", data)
+	}
+}
+
+func TestMetaToolHandler_ToolTimeout(t *testing.T) {
+	backend := newHelperStdioBackend(t, "slow")
+	if _, err := backend.Initialize(context.Background(), testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	manager := NewBackendManager()
+	manager.AddBackend(backend)
+	rt := NewRoutingTable()
+	rt.setBackendTools(backend.GetInfo(), config.NamespaceNone, []string{"echo"}, map[string]config.ToolOverride{
+		"echo": {Tool: "echo", Timeout: 50 * time.Millisecond},
+	})
+	handler := NewMetaToolHandler(manager, rt)
+
+	arguments := map[string]interface{}{"value": "slow", "delay_ms": 2000}
+	result, _, err := handler.HandleCallTool(context.Background(), &mcp.CallToolRequest{}, CallToolParams{ToolName: "echo", Arguments: arguments})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the call to time out, got %v", err)
+	}
+	if text, ok := result.Content[0].(*mcp.TextContent); !result.IsError || !ok || text.Text != "Tool 'echo' timed out after 50ms" {
+		t.Errorf("Expected a timeout error result, got %v", result.Content[0])
+	}
+
+	// Calls within the timeout still succeed
+	arguments = map[string]interface{}{"value": "fast"}
+	if _, _, err := handler.HandleCallTool(context.Background(), &mcp.CallToolRequest{}, CallToolParams{ToolName: "echo", Arguments: arguments}); err != nil {
+		t.Errorf("Expected the call to succeed, got %v", err)
+	}
+}
+
+func TestWithSchemaDefaults(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path":     map[string]interface{}{"type": "string"},
+			"encoding": map[string]interface{}{"type": "string"},
+		},
+		"required": []interface{}{"path", "encoding"},
+	}
+
+	result, ok := withSchemaDefaults(schema, map[string]interface{}{"encoding": "utf-8"}).(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected an object schema")
+	}
+
+	encoding := result["properties"].(map[string]interface{})["encoding"].(map[string]interface{})
+	if encoding["default"] != "utf-8" || encoding["type"] != "string" {
+		t.Errorf("Expected encoding to default to utf-8, got %v", encoding)
+	}
+	if required := result["required"].([]interface{}); len(required) != 1 || required[0] != "path" {
+		t.Errorf("Expected only path to stay required, got %v", required)
+	}
+
+	// The backend's schema is left untouched
+	if _, exists := schema["properties"].(map[string]interface{})["encoding"].(map[string]interface{})["default"]; exists {
+		t.Error("Original schema was modified")
+	}
+}
+
+// bearerTransport adds a bearer token to every request
+type bearerTransport struct {
+	token string
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestMetaToolHandler_EnforcesGroupAccess(t *testing.T) {
+	newBackend := func(toolName string) string {
+		server := mcp.NewServer(&mcp.Implementation{Name: toolName, Version: "1.0.0"}, nil)
+		addGreetTool(server, toolName)
+		ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return server }, nil))
+		t.Cleanup(ts.Close)
+		return ts.URL
+	}
+
+	authConfig := config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "designer-agent", Key: "designer-secret", Roles: []string{"designer"}},
+			{Name: "dev-bot", Key: "dev-secret"},
+		},
+	}
+	cfg := &config.Config{
+		Middleware: config.MiddlewareConfig{Auth: authConfig},
+		Groups: []config.Group{
+			{
+				Name:     "designer",
+				Access:   config.AccessPolicy{Roles: []string{"designer"}},
+				Backends: map[string]config.Backend{"figma-tools": {Name: "figma-tools", Transport: "http", Endpoint: newBackend("export_frame")}},
+			},
+			{
+				Name:     "developer",
+				Access:   config.AccessPolicy{Users: []string{"dev-bot"}},
+				Backends: map[string]config.Backend{"git-tools": {Name: "git-tools", Transport: "http", Endpoint: newBackend("git_status")}},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	ctx := context.Background()
+	if err := gateway.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+
+	authenticator, err := NewAuthenticator(authConfig)
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	handler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return gateway.GetServer() }, nil)
+	ts := httptest.NewServer(authenticator.Middleware(handler))
+	t.Cleanup(ts.Close) // After the client sessions are closed
+
+	connect := func(token string) *mcp.ClientSession {
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+		session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+			Endpoint:   ts.URL,
+			HTTPClient: &http.Client{Transport: &bearerTransport{token: token}},
+		}, nil)
+		if err != nil {
+			t.Fatalf("Client connect failed: %v", err)
+		}
+		t.Cleanup(func() { _ = session.Close() })
+		return session
+	}
+
+	designer := connect("designer-secret")
+
+	result, err := designer.CallTool(ctx, &mcp.CallToolParams{Name: "list_tools", Arguments: map[string]interface{}{}})
+	if err != nil {
+		t.Fatalf("list_tools failed: %v", err)
+	}
+	tools := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(tools, "export_frame") || strings.Contains(tools, "git_status") {
+		t.Errorf("Designer should only see design tools, got %s", tools)
+	}
+
+	for metaTool, arguments := range map[string]map[string]interface{}{
+		"describe_tool": {"tool_name": "git_status"},
+		"call_tool":     {"tool_name": "git_status", "arguments": map[string]interface{}{}},
+	} {
+		result, err := designer.CallTool(ctx, &mcp.CallToolParams{Name: metaTool, Arguments: arguments})
+		if err != nil {
+			t.Fatalf("%s failed: %v", metaTool, err)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !result.IsError || !strings.Contains(text, "permission denied") {
+			t.Errorf("%s of another group's tool should be denied, got %s", metaTool, text)
+		}
+	}
+
+	result, err = designer.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "call_tool",
+		Arguments: map[string]interface{}{"tool_name": "export_frame", "arguments": map[string]interface{}{}},
+	})
+	if err != nil || result.IsError {
+		t.Errorf("Designer should be able to call design tools, got %v %+v", err, result)
+	}
+
+	developer := connect("dev-secret")
+	result, err = developer.CallTool(ctx, &mcp.CallToolParams{Name: "list_tools", Arguments: map[string]interface{}{}})
+	if err != nil {
+		t.Fatalf("list_tools failed: %v", err)
+	}
+	tools = result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(tools, "git_status") || strings.Contains(tools, "export_frame") {
+		t.Errorf("Developer should only see developer tools, got %s", tools)
+	}
+}
@@ -0,0 +1,463 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MessageHandler receives messages that a backend sends on its own initiative,
+// i.e. anything read from a backend that is not a response to our requests
+type MessageHandler interface {
+	// HandleNotification is called for each notification sent by a backend
+	HandleNotification(ctx context.Context, backendName string, method string, params json.RawMessage)
+
+	// HandleRequest is called for each request sent by a backend. The returned
+	// result or error is sent back to the backend as the response.
+	HandleRequest(ctx context.Context, backendName string, method string, params json.RawMessage) (interface{}, error)
+}
+
+// jsonRPCError is an error carrying a JSON-RPC error code
+type jsonRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *jsonRPCError) Error() string {
+	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
+}
+
+const (
+	codeMethodNotFound = -32601
+	codeInternalError  = -32603
+)
+
+// messageDispatcher routes unsolicited backend messages to a MessageHandler.
+// Backends embed it to implement SetMessageHandler.
+type messageDispatcher struct {
+	handlerMu sync.RWMutex
+	handler   MessageHandler
+}
+
+// SetMessageHandler sets the handler for notifications and requests sent by the backend
+func (d *messageDispatcher) SetMessageHandler(handler MessageHandler) {
+	d.handlerMu.Lock()
+	defer d.handlerMu.Unlock()
+	d.handler = handler
+}
+
+func (d *messageDispatcher) messageHandler() MessageHandler {
+	d.handlerMu.RLock()
+	defer d.handlerMu.RUnlock()
+	return d.handler
+}
+
+// dispatch hands a notification or request from the backend to the handler.
+// Notifications are handled synchronously to preserve their order; requests
+// are handled in a new goroutine and answered through reply.
+func (d *messageDispatcher) dispatch(ctx context.Context, backendName string, msg *jsonRPCMessage, reply func(data []byte) error) {
+	params := json.RawMessage("{}")
+	if msg.Params != nil {
+		params = *msg.Params
+	}
+
+	handler := d.messageHandler()
+
+	if msg.ID == nil {
+		if handler == nil {
+			slog.Debug("Dropping notification without handler", "backend", backendName, "method", msg.Method)
+			return
+		}
+		handler.HandleNotification(ctx, backendName, msg.Method, params)
+		return
+	}
+
+	go func() {
+		var (
+			result interface{}
+			err    error
+		)
+		if handler == nil {
+			err = &jsonRPCError{Code: codeMethodNotFound, Message: "Method not found"}
+		} else {
+			result, err = handler.HandleRequest(ctx, backendName, msg.Method, params)
+		}
+
+		response := map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      msg.ID,
+		}
+		if err != nil {
+			var rpcErr *jsonRPCError
+			if !errors.As(err, &rpcErr) {
+				rpcErr = &jsonRPCError{Code: codeInternalError, Message: err.Error()}
+			}
+			response["error"] = rpcErr
+		} else {
+			response["result"] = result
+		}
+
+		data, err := json.Marshal(response)
+		if err != nil {
+			slog.Error("Failed to marshal response to backend request", "backend", backendName, "method", msg.Method, "error", err)
+			return
+		}
+		if err := reply(data); err != nil {
+			slog.Warn("Failed to reply to backend request", "backend", backendName, "method", msg.Method, "error", err)
+		}
+	}()
+}
+
+// NotificationRouter forwards backend-originated notifications and requests
+// to the clients connected to the gateway's MCP servers
+type NotificationRouter struct {
+	mu          sync.RWMutex
+	servers     []routedServer
+	groups      map[string]string // backend name -> group name
+	progress    map[string]progressTarget
+	nextToken   int64
+	activeCalls map[string]map[*mcp.ServerSession]int
+	listeners   []ListChangedListener
+	authorizer  *Authorizer                      // nil if clients are not authorized per group
+	identities  map[*mcp.ServerSession]*Identity // identity each session authenticated with
+}
+
+// ListChangedListener is notified when a backend reports that one of its
+// lists (tools, resources or prompts) changed
+type ListChangedListener func(backendName string, method string)
+
+// routedServer is a registered MCP server and the group it serves, or an
+// empty group for a server exposing every backend
+type routedServer struct {
+	server *mcp.Server
+	group  string
+}
+
+// progressTarget is the client session and token a gateway progress token maps to
+type progressTarget struct {
+	session *mcp.ServerSession
+	token   interface{}
+}
+
+// NewNotificationRouter creates a new notification router
+func NewNotificationRouter() *NotificationRouter {
+	return &NotificationRouter{
+		progress:    make(map[string]progressTarget),
+		groups:      make(map[string]string),
+		activeCalls: make(map[string]map[*mcp.ServerSession]int),
+		identities:  make(map[*mcp.ServerSession]*Identity),
+	}
+}
+
+// SetAuthorizer restricts forwarded messages to the sessions whose client
+// may access the sending backend's group
+func (nr *NotificationRouter) SetAuthorizer(authorizer *Authorizer) {
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+	nr.authorizer = authorizer
+}
+
+// AddServer registers an MCP server whose sessions receive forwarded messages
+func (nr *NotificationRouter) AddServer(server *mcp.Server) {
+	server.AddReceivingMiddleware(nr.recordIdentity)
+
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+	nr.servers = append(nr.servers, routedServer{server: server})
+}
+
+// AddGroupServer registers an MCP server serving a single group. Its
+// sessions only receive messages from the group's backends.
+func (nr *NotificationRouter) AddGroupServer(group string, server *mcp.Server) {
+	server.AddReceivingMiddleware(nr.recordIdentity)
+
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+	nr.servers = append(nr.servers, routedServer{server: server, group: group})
+}
+
+// SetBackendGroup records the group a backend belongs to
+func (nr *NotificationRouter) SetBackendGroup(backendName, group string) {
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+	nr.groups[backendName] = group
+}
+
+// OnListChanged registers a listener for list_changed notifications from backends
+func (nr *NotificationRouter) OnListChanged(listener ListChangedListener) {
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+	nr.listeners = append(nr.listeners, listener)
+}
+
+// serversFor returns the servers whose clients may receive messages from a
+// backend: those exposing every backend or the backend's group
+func (nr *NotificationRouter) serversFor(backendName string) []*mcp.Server {
+	nr.mu.RLock()
+	defer nr.mu.RUnlock()
+
+	group := nr.groups[backendName]
+	var servers []*mcp.Server
+	for _, routed := range nr.servers {
+		if routed.group != "" && routed.group != group {
+			continue
+		}
+		servers = append(servers, routed.server)
+	}
+	return servers
+}
+
+// sessions returns the client sessions that may receive messages from a
+// backend: those of the servers that can see it whose client may access the
+// backend's group
+func (nr *NotificationRouter) sessions(backendName string) []*mcp.ServerSession {
+	var sessions []*mcp.ServerSession
+	for _, server := range nr.serversFor(backendName) {
+		for session := range server.Sessions() {
+			if nr.allowed(session, backendName) {
+				sessions = append(sessions, session)
+			}
+		}
+	}
+	return sessions
+}
+
+// allowed reports whether the client of a session may access a backend's group
+func (nr *NotificationRouter) allowed(session *mcp.ServerSession, backendName string) bool {
+	nr.mu.RLock()
+	defer nr.mu.RUnlock()
+
+	if nr.authorizer == nil {
+		return true
+	}
+	return nr.authorizer.Allowed(nr.identities[session], nr.groups[backendName])
+}
+
+// recordIdentity is a receiving middleware that records the identity each
+// client session authenticated with, so that messages from backends are only
+// forwarded to sessions allowed to see them
+func (nr *NotificationRouter) recordIdentity(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, request mcp.Request) (mcp.Result, error) {
+		session, ok := request.GetSession().(*mcp.ServerSession)
+		if ok && session != nil {
+			identity := extraIdentity(request.GetExtra())
+
+			nr.mu.Lock()
+			_, known := nr.identities[session]
+			nr.identities[session] = identity
+			nr.mu.Unlock()
+
+			if !known {
+				go func() {
+					_ = session.Wait()
+					nr.mu.Lock()
+					delete(nr.identities, session)
+					nr.mu.Unlock()
+				}()
+			}
+		}
+		return next(ctx, method, request)
+	}
+}
+
+// TrackCall records that session has a request in flight on a backend, so that
+// requests the backend sends meanwhile can be forwarded to that session. The
+// returned function must be called once the request completes.
+func (nr *NotificationRouter) TrackCall(backendName string, session *mcp.ServerSession) func() {
+	if session == nil {
+		return func() {}
+	}
+
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+
+	if nr.activeCalls[backendName] == nil {
+		nr.activeCalls[backendName] = make(map[*mcp.ServerSession]int)
+	}
+	nr.activeCalls[backendName][session]++
+
+	return func() {
+		nr.mu.Lock()
+		defer nr.mu.Unlock()
+
+		nr.activeCalls[backendName][session]--
+		if nr.activeCalls[backendName][session] <= 0 {
+			delete(nr.activeCalls[backendName], session)
+		}
+	}
+}
+
+// RegisterProgressToken maps a client's progress token to a gateway-unique
+// token that is sent to the backend instead. Progress notifications for the
+// returned token are delivered to session with the original token. The
+// returned function releases the mapping.
+func (nr *NotificationRouter) RegisterProgressToken(session *mcp.ServerSession, token interface{}) (string, func()) {
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+
+	nr.nextToken++
+	gatewayToken := fmt.Sprintf("gateway-%d", nr.nextToken)
+	nr.progress[gatewayToken] = progressTarget{session: session, token: token}
+
+	return gatewayToken, func() {
+		nr.mu.Lock()
+		defer nr.mu.Unlock()
+		delete(nr.progress, gatewayToken)
+	}
+}
+
+// HandleNotification implements MessageHandler
+func (nr *NotificationRouter) HandleNotification(ctx context.Context, backendName string, method string, params json.RawMessage) {
+	switch method {
+	case "notifications/message":
+		nr.forwardLog(ctx, backendName, params)
+	case "notifications/progress":
+		nr.forwardProgress(ctx, backendName, params)
+	case "notifications/resources/updated":
+		nr.forwardResourceUpdated(ctx, backendName, params)
+	case "notifications/tools/list_changed",
+		"notifications/resources/list_changed",
+		"notifications/prompts/list_changed":
+		nr.mu.RLock()
+		listeners := append([]ListChangedListener(nil), nr.listeners...)
+		nr.mu.RUnlock()
+
+		slog.Info("Backend list changed", "backend", backendName, "method", method)
+		for _, listener := range listeners {
+			listener(backendName, method)
+		}
+	default:
+		slog.Debug("Ignoring backend notification", "backend", backendName, "method", method)
+	}
+}
+
+// forwardLog re-emits a backend log message to every client session that can
+// see the backend, using the backend name as the logger so clients can tell
+// the sources apart
+func (nr *NotificationRouter) forwardLog(ctx context.Context, backendName string, params json.RawMessage) {
+	var logParams mcp.LoggingMessageParams
+	if err := json.Unmarshal(params, &logParams); err != nil {
+		slog.Warn("Invalid log message from backend", "backend", backendName, "error", err)
+		return
+	}
+
+	if logParams.Logger == "" {
+		logParams.Logger = backendName
+	} else {
+		logParams.Logger = backendName + "/" + logParams.Logger
+	}
+
+	for _, session := range nr.sessions(backendName) {
+		if err := session.Log(ctx, &logParams); err != nil {
+			slog.Warn("Failed to forward log message", "backend", backendName, "session_id", session.ID(), "error", err)
+		}
+	}
+}
+
+// forwardProgress delivers a backend progress notification to the session
+// that owns the progress token
+func (nr *NotificationRouter) forwardProgress(ctx context.Context, backendName string, params json.RawMessage) {
+	var progressParams mcp.ProgressNotificationParams
+	if err := json.Unmarshal(params, &progressParams); err != nil {
+		slog.Warn("Invalid progress notification from backend", "backend", backendName, "error", err)
+		return
+	}
+
+	nr.mu.RLock()
+	target, exists := nr.progress[fmt.Sprint(progressParams.ProgressToken)]
+	nr.mu.RUnlock()
+
+	if !exists {
+		slog.Debug("Dropping progress for unknown token", "backend", backendName, "progress_token", progressParams.ProgressToken)
+		return
+	}
+
+	progressParams.ProgressToken = target.token
+	if err := target.session.NotifyProgress(ctx, &progressParams); err != nil {
+		slog.Warn("Failed to forward progress", "backend", backendName, "session_id", target.session.ID(), "error", err)
+	}
+}
+
+// forwardResourceUpdated delivers a backend's resource update to the client
+// sessions that subscribed to the resource through a server that can see
+// the backend
+func (nr *NotificationRouter) forwardResourceUpdated(ctx context.Context, backendName string, params json.RawMessage) {
+	var updatedParams mcp.ResourceUpdatedNotificationParams
+	if err := json.Unmarshal(params, &updatedParams); err != nil {
+		slog.Warn("Invalid resource update from backend", "backend", backendName, "error", err)
+		return
+	}
+
+	for _, server := range nr.serversFor(backendName) {
+		if err := server.ResourceUpdated(ctx, &updatedParams); err != nil {
+			slog.Warn("Failed to forward resource update", "backend", backendName, "uri", updatedParams.URI, "error", err)
+		}
+	}
+}
+
+// HandleRequest implements MessageHandler
+func (nr *NotificationRouter) HandleRequest(ctx context.Context, backendName string, method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "ping":
+		return struct{}{}, nil
+	case "roots/list":
+		session, err := nr.sessionFor(backendName)
+		if err != nil {
+			return nil, err
+		}
+		var listParams mcp.ListRootsParams
+		if err := json.Unmarshal(params, &listParams); err != nil {
+			return nil, err
+		}
+		return session.ListRoots(ctx, &listParams)
+	case "sampling/createMessage":
+		session, err := nr.sessionFor(backendName)
+		if err != nil {
+			return nil, err
+		}
+		var createParams mcp.CreateMessageParams
+		if err := json.Unmarshal(params, &createParams); err != nil {
+			return nil, err
+		}
+		return session.CreateMessage(ctx, &createParams)
+	case "elicitation/create":
+		session, err := nr.sessionFor(backendName)
+		if err != nil {
+			return nil, err
+		}
+		var elicitParams mcp.ElicitParams
+		if err := json.Unmarshal(params, &elicitParams); err != nil {
+			return nil, err
+		}
+		return session.Elicit(ctx, &elicitParams)
+	default:
+		return nil, &jsonRPCError{Code: codeMethodNotFound, Message: "Method not found"}
+	}
+}
+
+// sessionFor picks the client session a backend request should be forwarded
+// to: the only session with a call in flight on the backend, or else the
+// only session that can see the backend
+func (nr *NotificationRouter) sessionFor(backendName string) (*mcp.ServerSession, error) {
+	nr.mu.RLock()
+	active := make([]*mcp.ServerSession, 0, len(nr.activeCalls[backendName]))
+	for session := range nr.activeCalls[backendName] {
+		active = append(active, session)
+	}
+	nr.mu.RUnlock()
+
+	if len(active) == 1 {
+		return active[0], nil
+	}
+
+	if sessions := nr.sessions(backendName); len(active) == 0 && len(sessions) == 1 {
+		return sessions[0], nil
+	}
+
+	return nil, fmt.Errorf("cannot determine which client session should handle the request from backend %s", backendName)
+}
@@ -0,0 +1,395 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// recordingMessageHandler records the messages a backend sends on its own initiative
+type recordingMessageHandler struct {
+	mu            sync.Mutex
+	notifications []string
+	requests      []string
+}
+
+func (h *recordingMessageHandler) HandleNotification(ctx context.Context, backendName string, method string, params json.RawMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.notifications = append(h.notifications, backendName+" "+method+" "+string(params))
+}
+
+func (h *recordingMessageHandler) HandleRequest(ctx context.Context, backendName string, method string, params json.RawMessage) (interface{}, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = append(h.requests, backendName+" "+method)
+	return map[string]interface{}{"handled": method}, nil
+}
+
+func TestStdioBackend_DispatchesNotifications(t *testing.T) {
+	backend := newHelperStdioBackend(t, "stdio-backend")
+	handler := &recordingMessageHandler{}
+	backend.SetMessageHandler(handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := backend.Initialize(ctx, map[string]interface{}{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	params := map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"value": "done", "notify": "working"},
+	}
+	response, err := backend.SendRequest(ctx, "tools/call", params)
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	if !strings.Contains(string(*response), "done") {
+		t.Errorf("Notification was mistaken for the response: %s", string(*response))
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.notifications) != 1 || !strings.Contains(handler.notifications[0], "notifications/message") {
+		t.Errorf("Expected one forwarded log notification, got %v", handler.notifications)
+	}
+}
+
+func TestStdioBackend_AnswersBackendRequests(t *testing.T) {
+	backend := newHelperStdioBackend(t, "stdio-backend")
+	handler := &recordingMessageHandler{}
+	backend.SetMessageHandler(handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := backend.Initialize(ctx, map[string]interface{}{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	params := map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"ping_client": true},
+	}
+	response, err := backend.SendRequest(ctx, "tools/call", params)
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+
+	// The helper echoes the response it received for its own request
+	if !strings.Contains(string(*response), `handled`) {
+		t.Errorf("Expected the backend to receive our reply, got %s", string(*response))
+	}
+}
+
+func TestHTTPBackend_DispatchesNotificationsInBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":"t","progress":1}},
+			{"jsonrpc":"2.0","id":1,"result":{"ok":true}}
+		]`))
+	}))
+	defer server.Close()
+
+	backend := NewHTTPBackend(config.Backend{
+		Name:      "http-backend",
+		Transport: "http",
+		Endpoint:  server.URL,
+	}, "test-group")
+	handler := &recordingMessageHandler{}
+	backend.SetMessageHandler(handler)
+
+	response, err := backend.SendRequest(context.Background(), "tools/call", struct{}{})
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	if string(*response) != `{"ok":true}` {
+		t.Errorf("Unexpected result: %s", string(*response))
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.notifications) != 1 || !strings.Contains(handler.notifications[0], "notifications/progress") {
+		t.Errorf("Expected one progress notification, got %v", handler.notifications)
+	}
+}
+
+func TestMessageDispatcher_NoHandler(t *testing.T) {
+	var d messageDispatcher
+	replies := make(chan []byte, 1)
+
+	id := json.RawMessage(`7`)
+	d.dispatch(context.Background(), "backend", &jsonRPCMessage{ID: &id, Method: "roots/list"}, func(data []byte) error {
+		replies <- data
+		return nil
+	})
+
+	select {
+	case data := <-replies:
+		var response jsonRPCMessage
+		if err := json.Unmarshal(data, &response); err != nil {
+			t.Fatalf("Failed to unmarshal reply: %v", err)
+		}
+		if response.Error == nil || !strings.Contains(string(*response.Error), "-32601") {
+			t.Errorf("Expected method not found error, got %s", string(data))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No reply sent for backend request")
+	}
+}
+
+// connectTestClient connects an in-memory client session to server
+func connectTestClient(t *testing.T, server *mcp.Server, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("Server connect failed: %v", err)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, opts)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Client connect failed: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func TestNotificationRouter_ForwardsLogMessages(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "gateway", Version: "1.0.0"}, nil)
+	router := NewNotificationRouter()
+	router.AddServer(server)
+
+	received := make(chan *mcp.LoggingMessageParams, 1)
+	session := connectTestClient(t, server, &mcp.ClientOptions{
+		LoggingMessageHandler: func(ctx context.Context, req *mcp.LoggingMessageRequest) {
+			received <- req.Params
+		},
+	})
+
+	ctx := context.Background()
+	if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}); err != nil {
+		t.Fatalf("SetLoggingLevel failed: %v", err)
+	}
+
+	router.HandleNotification(ctx, "git-tools", "notifications/message",
+		json.RawMessage(`{"level":"warning","logger":"indexer","data":"slow"}`))
+
+	select {
+	case params := <-received:
+		if params.Logger != "git-tools/indexer" {
+			t.Errorf("Expected logger git-tools/indexer, got %s", params.Logger)
+		}
+		if params.Level != "warning" {
+			t.Errorf("Expected level warning, got %s", params.Level)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Log message was not forwarded")
+	}
+}
+
+func TestNotificationRouter_ScopesLogMessagesToGroups(t *testing.T) {
+	router := NewNotificationRouter()
+	router.SetBackendGroup("figma-tools", "designer")
+
+	ctx := context.Background()
+	connect := func(server *mcp.Server) chan *mcp.LoggingMessageParams {
+		received := make(chan *mcp.LoggingMessageParams, 1)
+		session := connectTestClient(t, server, &mcp.ClientOptions{
+			LoggingMessageHandler: func(ctx context.Context, req *mcp.LoggingMessageRequest) {
+				received <- req.Params
+			},
+		})
+		if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}); err != nil {
+			t.Fatalf("SetLoggingLevel failed: %v", err)
+		}
+		return received
+	}
+
+	gatewayServer := mcp.NewServer(&mcp.Implementation{Name: "gateway", Version: "1.0.0"}, nil)
+	designerServer := mcp.NewServer(&mcp.Implementation{Name: "designer", Version: "1.0.0"}, nil)
+	developerServer := mcp.NewServer(&mcp.Implementation{Name: "developer", Version: "1.0.0"}, nil)
+	router.AddServer(gatewayServer)
+	router.AddGroupServer("designer", designerServer)
+	router.AddGroupServer("developer", developerServer)
+
+	gatewayLogs := connect(gatewayServer)
+	designerLogs := connect(designerServer)
+	developerLogs := connect(developerServer)
+
+	router.HandleNotification(ctx, "figma-tools", "notifications/message",
+		json.RawMessage(`{"level":"info","data":"exported"}`))
+
+	for name, received := range map[string]chan *mcp.LoggingMessageParams{"gateway": gatewayLogs, "designer": designerLogs} {
+		select {
+		case <-received:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Log message was not forwarded to the %s endpoint", name)
+		}
+	}
+
+	select {
+	case params := <-developerLogs:
+		t.Errorf("Developer endpoint received a designer backend's log message: %+v", params)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotificationRouter_ForwardsProgressToOwner(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "gateway", Version: "1.0.0"}, nil)
+	router := NewNotificationRouter()
+	router.AddServer(server)
+
+	received := make(chan *mcp.ProgressNotificationParams, 1)
+	connectTestClient(t, server, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			received <- req.Params
+		},
+	})
+
+	var serverSession *mcp.ServerSession
+	for session := range server.Sessions() {
+		serverSession = session
+	}
+
+	token, release := router.RegisterProgressToken(serverSession, "client-token")
+	defer release()
+
+	params, _ := json.Marshal(map[string]interface{}{"progressToken": token, "progress": 5, "total": 10})
+	router.HandleNotification(context.Background(), "build-tools", "notifications/progress", params)
+
+	select {
+	case params := <-received:
+		if params.ProgressToken != "client-token" {
+			t.Errorf("Expected original progress token, got %v", params.ProgressToken)
+		}
+		if params.Progress != 5 || params.Total != 10 {
+			t.Errorf("Unexpected progress values: %+v", params)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Progress notification was not forwarded")
+	}
+}
+
+func TestNotificationRouter_ListChangedListeners(t *testing.T) {
+	router := NewNotificationRouter()
+
+	var got []string
+	router.OnListChanged(func(backendName string, method string) {
+		got = append(got, backendName+" "+method)
+	})
+
+	router.HandleNotification(context.Background(), "b1", "notifications/tools/list_changed", json.RawMessage(`{}`))
+
+	if len(got) != 1 || got[0] != "b1 notifications/tools/list_changed" {
+		t.Errorf("Unexpected listener calls: %v", got)
+	}
+}
+
+func TestNotificationRouter_HandleRequest(t *testing.T) {
+	router := NewNotificationRouter()
+	ctx := context.Background()
+
+	if _, err := router.HandleRequest(ctx, "b1", "ping", json.RawMessage(`{}`)); err != nil {
+		t.Errorf("Ping should be answered by the gateway: %v", err)
+	}
+
+	if _, err := router.HandleRequest(ctx, "b1", "roots/list", json.RawMessage(`{}`)); err == nil {
+		t.Error("Expected error when no client session is connected")
+	}
+
+	if _, err := router.HandleRequest(ctx, "b1", "unknown/method", json.RawMessage(`{}`)); err == nil {
+		t.Error("Expected method not found error")
+	}
+}
+
+func TestNotificationRouter_AuthorizesForwardedMessages(t *testing.T) {
+	authConfig := config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "designer-agent", Key: "designer-secret", Roles: []string{"designer"}},
+			{Name: "dev-bot", Key: "dev-secret"},
+		},
+	}
+	groups := []config.Group{{Name: "designer", Access: config.AccessPolicy{Roles: []string{"designer"}}}}
+
+	router := NewNotificationRouter()
+	router.SetAuthorizer(NewAuthorizer(groups))
+	router.SetBackendGroup("figma-tools", "designer")
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "gateway", Version: "1.0.0"}, nil)
+	router.AddServer(server)
+
+	authenticator, err := NewAuthenticator(authConfig)
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	ts := httptest.NewServer(authenticator.Middleware(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return server }, nil)))
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	connect := func(token string) (*mcp.ClientSession, chan *mcp.LoggingMessageParams) {
+		received := make(chan *mcp.LoggingMessageParams, 1)
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+			LoggingMessageHandler: func(ctx context.Context, req *mcp.LoggingMessageRequest) {
+				received <- req.Params
+			},
+		})
+		session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+			Endpoint:   ts.URL,
+			HTTPClient: &http.Client{Transport: &bearerTransport{token: token}},
+		}, nil)
+		if err != nil {
+			t.Fatalf("Client connect failed: %v", err)
+		}
+		t.Cleanup(func() { _ = session.Close() })
+		if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}); err != nil {
+			t.Fatalf("SetLoggingLevel failed: %v", err)
+		}
+		return session, received
+	}
+
+	// Requests from a backend of a restricted group have no session to go to
+	// while only clients without access are connected
+	_, developerLogs := connect("dev-secret")
+	if _, err := router.HandleRequest(ctx, "figma-tools", "roots/list", json.RawMessage(`{}`)); err == nil {
+		t.Error("Expected a restricted backend's request not to be forwarded to an unauthorized session")
+	}
+
+	_, designerLogs := connect("designer-secret")
+	router.HandleNotification(ctx, "figma-tools", "notifications/message",
+		json.RawMessage(`{"level":"info","data":"exported"}`))
+
+	select {
+	case <-designerLogs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Log message was not forwarded to the authorized session")
+	}
+	select {
+	case params := <-developerLogs:
+		t.Errorf("Unauthorized session received a restricted backend's log message: %+v", params)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Backends of open groups still reach every session
+	router.HandleNotification(ctx, "git-tools", "notifications/message",
+		json.RawMessage(`{"level":"info","data":"pulled"}`))
+	select {
+	case <-developerLogs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Log message of an open group was not forwarded")
+	}
+}
@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxListPages caps the pages followed when listing a backend's tools,
+// resources, templates or prompts, so that a backend returning cursors
+// endlessly cannot stall discovery
+const maxListPages = 100
+
+// listParams are the parameters of a paginated list request
+type listParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// listAll sends a list request to a backend and follows nextCursor until
+// the last page, collecting the items each page carries under field. At
+// most maxListPages pages are read; the items of those are returned.
+func listAll[T any](ctx context.Context, backend Backend, method, field string) ([]T, error) {
+	var (
+		items  []T
+		cursor string
+	)
+	for page := 0; page < maxListPages; page++ {
+		response, err := backend.SendRequest(ctx, method, &listParams{Cursor: cursor})
+		if err != nil {
+			return nil, err
+		}
+
+		var result map[string]json.RawMessage
+		if err := json.Unmarshal(*response, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s response: %w", method, err)
+		}
+
+		var pageItems []T
+		if raw, exists := result[field]; exists {
+			if err := json.Unmarshal(raw, &pageItems); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal %s response: %w", method, err)
+			}
+		}
+		items = append(items, pageItems...)
+
+		cursor = ""
+		if raw, exists := result["nextCursor"]; exists {
+			if err := json.Unmarshal(raw, &cursor); err != nil {
+				return nil, fmt.Errorf("invalid cursor in %s response: %w", method, err)
+			}
+		}
+		if cursor == "" {
+			return items, nil
+		}
+	}
+
+	slog.Warn("Backend list exceeds the page limit; ignoring the remaining pages", "backend", backend.GetInfo().Name, "method", method, "pages", maxListPages, "items", len(items))
+	return items, nil
+}
+
+// pageNames returns the page of sorted names that follows cursor, and the
+// cursor of the next page, which is empty on the last page. A page holds at
+// most pageSize names, or mcp.DefaultPageSize if pageSize is zero.
+func pageNames(names []string, cursor string, pageSize int) ([]string, string, error) {
+	if pageSize <= 0 {
+		pageSize = mcp.DefaultPageSize
+	}
+
+	start := 0
+	if cursor != "" {
+		last, err := base64.URLEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid cursor %q", cursor)
+		}
+		// Names after the last one of the previous page, even if it was removed since
+		start, _ = slices.BinarySearch(names, string(last))
+		if start < len(names) && names[start] == string(last) {
+			start++
+		}
+	}
+
+	end := min(start+pageSize, len(names))
+	page := names[start:end]
+	if end == len(names) {
+		return page, "", nil
+	}
+	return page, base64.URLEncoding.EncodeToString([]byte(page[len(page)-1])), nil
+}
@@ -0,0 +1,270 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// endlessListBackend is a backend whose tools/list always has another page
+type endlessListBackend struct {
+	probeBackend
+	pages int
+}
+
+func (b *endlessListBackend) SendRequest(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
+	b.pages++
+	raw := json.RawMessage(fmt.Sprintf(`{"tools":[{"name":"tool%d"}],"nextCursor":"page%d"}`, b.pages, b.pages))
+	return &raw, nil
+}
+
+func TestListAll_StopsAtPageLimit(t *testing.T) {
+	backend := &endlessListBackend{}
+
+	tools, err := listAll[mcp.Tool](context.Background(), backend, "tools/list", "tools")
+	if err != nil {
+		t.Fatalf("listAll failed: %v", err)
+	}
+	if backend.pages != maxListPages || len(tools) != maxListPages {
+		t.Errorf("Expected %d pages to be read, got %d requests and %d tools", maxListPages, backend.pages, len(tools))
+	}
+}
+
+func TestPageNames(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e"}
+
+	var pages [][]string
+	cursor := ""
+	for {
+		page, next, err := pageNames(names, cursor, 2)
+		if err != nil {
+			t.Fatalf("pageNames failed: %v", err)
+		}
+		pages = append(pages, page)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(pages) != 3 || !slices.Equal(slices.Concat(pages...), names) {
+		t.Errorf("Expected every name in pages of two, got %v", pages)
+	}
+
+	// A page continues after the previous one even if its last name was removed
+	_, cursor, _ = pageNames(names, "", 2)
+	if page, _, _ := pageNames([]string{"a", "c", "d"}, cursor, 2); !slices.Equal(page, []string{"c", "d"}) {
+		t.Errorf("Expected the names after b, got %v", page)
+	}
+
+	if _, _, err := pageNames(names, "not base64!", 2); err == nil {
+		t.Error("Expected an invalid cursor to fail")
+	}
+}
+
+func TestGateway_PaginatesLists(t *testing.T) {
+	backendServer := mcp.NewServer(&mcp.Implementation{Name: "large", Version: "1.0.0"}, &mcp.ServerOptions{PageSize: 2})
+	var toolNames, uris []string
+	for i := 1; i <= 5; i++ {
+		toolNames = append(toolNames, fmt.Sprintf("tool%d", i))
+		addGreetTool(backendServer, toolNames[i-1])
+		uris = append(uris, fmt.Sprintf("file:///doc%d", i))
+		addTextResource(backendServer, uris[i-1], "document")
+		addEchoPrompt(backendServer, "large", fmt.Sprintf("prompt%d", i))
+	}
+	ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return backendServer }, nil))
+	defer ts.Close()
+
+	cfg := &config.Config{
+		Gateway: config.GatewayConfig{PageSize: 2},
+		Groups: []config.Group{
+			{
+				Name: "test-group",
+				Backends: map[string]config.Backend{
+					"large": {Name: "large", Transport: "http", Endpoint: ts.URL},
+				},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	ctx := context.Background()
+	if err := gateway.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+
+	// Discovery follows the backend's cursors
+	if tools := gateway.GetRoutingTable().GetAllTools(); !slices.Equal(tools, toolNames) {
+		t.Errorf("Expected every page of tools to be discovered, got %v", tools)
+	}
+
+	session := connectTestClient(t, gateway.GetServer(), nil)
+
+	// The gateway's own lists are paginated
+	first, err := session.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	if len(first.Resources) != 2 || first.NextCursor == "" {
+		t.Errorf("Expected a first page of two resources, got %d with cursor %q", len(first.Resources), first.NextCursor)
+	}
+	var listed []string
+	for resource, err := range session.Resources(ctx, nil) {
+		if err != nil {
+			t.Fatalf("Listing resources failed: %v", err)
+		}
+		listed = append(listed, resource.URI)
+	}
+	if !slices.Equal(listed, uris) {
+		t.Errorf("Expected every resource across pages, got %v", listed)
+	}
+	var prompts int
+	for _, err := range session.Prompts(ctx, nil) {
+		if err != nil {
+			t.Fatalf("Listing prompts failed: %v", err)
+		}
+		prompts++
+	}
+	if prompts != 5 {
+		t.Errorf("Expected 5 prompts across pages, got %d", prompts)
+	}
+
+	// list_tools returns a cursor for the next page
+	var pages []string
+	arguments := map[string]interface{}{}
+	for range 5 {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "list_tools", Arguments: arguments})
+		if err != nil || result.IsError {
+			t.Fatalf("list_tools failed: %v %+v", err, result)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		pages = append(pages, text)
+		_, cursor, found := strings.Cut(text, "cursor ")
+		if !found {
+			break
+		}
+		arguments = map[string]interface{}{"cursor": strings.Trim(cursor, `"`)}
+	}
+	if len(pages) != 3 || !strings.Contains(pages[2], "tool5") {
+		t.Errorf("Expected three pages of tools, got %q", pages)
+	}
+
+	// Tools beyond the backend's first page can be described
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "describe_tool", Arguments: map[string]interface{}{"tool_name": "tool5"}})
+	if err != nil || result.IsError {
+		t.Errorf("describe_tool of a tool on a later page failed: %v %+v", err, result)
+	}
+}
+
+func TestGateway_PaginatesFilteredLists(t *testing.T) {
+	newBackend := func(name string, first int) string {
+		server := mcp.NewServer(&mcp.Implementation{Name: name, Version: "1.0.0"}, nil)
+		for i := first; i <= 6; i += 2 {
+			addTextResource(server, fmt.Sprintf("file:///doc%d", i), "document")
+			addEchoPrompt(server, name, fmt.Sprintf("prompt%d", i))
+		}
+		ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return server }, nil))
+		t.Cleanup(ts.Close)
+		return ts.URL
+	}
+
+	authConfig := config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{{Name: "designer-agent", Key: "designer-secret", Roles: []string{"designer"}}},
+	}
+	cfg := &config.Config{
+		Gateway:    config.GatewayConfig{PageSize: 2},
+		Middleware: config.MiddlewareConfig{Auth: authConfig},
+		Groups: []config.Group{
+			{
+				Name:     "designer",
+				Access:   config.AccessPolicy{Roles: []string{"designer"}},
+				Backends: map[string]config.Backend{"figma": {Name: "figma", Transport: "http", Endpoint: newBackend("figma", 1)}},
+			},
+			{
+				Name:     "developer",
+				Access:   config.AccessPolicy{Users: []string{"dev-bot"}},
+				Backends: map[string]config.Backend{"git": {Name: "git", Transport: "http", Endpoint: newBackend("git", 2)}},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	ctx := context.Background()
+	if err := gateway.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+
+	authenticator, err := NewAuthenticator(authConfig)
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	handler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return gateway.GetServer() }, nil)
+	ts := httptest.NewServer(authenticator.Middleware(handler))
+	t.Cleanup(ts.Close) // After the client session is closed
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   ts.URL,
+		HTTPClient: &http.Client{Transport: &bearerTransport{token: "designer-secret"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Client connect failed: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	// Pages are cut before hidden items are removed, so they may come up
+	// short, but following the cursors lists exactly the visible items
+	var uris []string
+	params := &mcp.ListResourcesParams{}
+	for {
+		page, err := session.ListResources(ctx, params)
+		if err != nil {
+			t.Fatalf("ListResources failed: %v", err)
+		}
+		if len(page.Resources) > 2 {
+			t.Errorf("Expected at most 2 resources per page, got %d", len(page.Resources))
+		}
+		for _, resource := range page.Resources {
+			uris = append(uris, resource.URI)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		params.Cursor = page.NextCursor
+	}
+	if expected := []string{"file:///doc1", "file:///doc3", "file:///doc5"}; !slices.Equal(uris, expected) {
+		t.Errorf("Expected the designer's resources %v across pages, got %v", expected, uris)
+	}
+
+	var prompts []string
+	for prompt, err := range session.Prompts(ctx, nil) {
+		if err != nil {
+			t.Fatalf("Listing prompts failed: %v", err)
+		}
+		if !strings.HasSuffix(prompt.Description, " of figma") {
+			t.Errorf("Designer should not see prompt %s: %s", prompt.Name, prompt.Description)
+		}
+		prompts = append(prompts, prompt.Name)
+	}
+	if len(prompts) != 3 {
+		t.Errorf("Expected the designer's 3 prompts across pages, got %v", prompts)
+	}
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// PromptHandler serves the prompts of the backends behind an endpoint.
+// Requests for a prompt are routed to the backend that listed it.
+type PromptHandler struct {
+	backendManager *BackendManager
+	routingTable   *RoutingTable
+	authorizer     *Authorizer // nil if clients are not authorized per group
+}
+
+// NewPromptHandler creates a new prompt handler
+func NewPromptHandler(backendManager *BackendManager, routingTable *RoutingTable) *PromptHandler {
+	return &PromptHandler{
+		backendManager: backendManager,
+		routingTable:   routingTable,
+	}
+}
+
+// HandleGetPrompt implements prompts/get by getting the prompt from the
+// backend that provides it, passing the client's arguments through
+func (ph *PromptHandler) HandleGetPrompt(ctx context.Context, request *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	name := request.Params.Name
+
+	route, exists := ph.routingTable.ResolvePrompt(name)
+	if !exists {
+		return nil, fmt.Errorf("prompt '%s' not found", name)
+	}
+
+	backend, exists := ph.backendManager.GetBackend(route.Backend)
+	if !exists {
+		return nil, fmt.Errorf("backend '%s' not available", route.Backend)
+	}
+
+	if err := ph.authorize(request, name, backend.GetInfo().Group); err != nil {
+		return nil, err
+	}
+
+	params := &mcp.GetPromptParams{Name: route.Prompt, Arguments: request.Params.Arguments}
+	response, err := backend.SendRequest(ctx, "prompts/get", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt from backend: %w", err)
+	}
+
+	var result mcp.GetPromptResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt: %w", err)
+	}
+	return &result, nil
+}
+
+// authorize checks that the client that sent request may get a prompt of
+// the given group
+func (ph *PromptHandler) authorize(request *mcp.GetPromptRequest, name, group string) error {
+	if ph.authorizer == nil {
+		return nil
+	}
+
+	return ph.authorizer.Authorize(extraIdentity(request.Extra), group, fmt.Sprintf("get prompt '%s'", name), sessionID(request.Session))
+}
+
+// filterPrompts is a receiving middleware that hides the prompts of
+// unhealthy backends and of groups the client may not access from
+// prompts/list results. Like resource pages, a filtered page may come up
+// short while its cursor still leads to the rest.
+func (ph *PromptHandler) filterPrompts(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, request mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, request)
+		if list, ok := result.(*mcp.ListPromptsResult); ok && err == nil {
+			visible := make([]*mcp.Prompt, 0, len(list.Prompts))
+			for _, prompt := range list.Prompts {
+				if route, exists := ph.routingTable.ResolvePrompt(prompt.Name); exists && backendListed(ph.backendManager, ph.authorizer, request, route.Backend) {
+					visible = append(visible, prompt)
+				}
+			}
+			list.Prompts = visible
+		}
+		return result, err
+	}
+}
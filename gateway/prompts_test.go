@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// addEchoPrompt adds a prompt to server whose message names the server and
+// repeats the topic argument
+func addEchoPrompt(server *mcp.Server, serverName, name string) {
+	prompt := &mcp.Prompt{
+		Name:        name,
+		Description: "Prompt " + name + " of " + serverName,
+		Arguments:   []*mcp.PromptArgument{{Name: "topic", Required: true}},
+	}
+	server.AddPrompt(prompt, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{
+			Messages: []*mcp.PromptMessage{
+				{Role: "user", Content: &mcp.TextContent{Text: serverName + ": " + req.Params.Arguments["topic"]}},
+			},
+		}, nil
+	})
+}
+
+func TestGateway_AggregatesPrompts(t *testing.T) {
+	newBackend := func(name string, prompts ...string) *httptest.Server {
+		server := mcp.NewServer(&mcp.Implementation{Name: name, Version: "1.0.0"}, nil)
+		for _, prompt := range prompts {
+			addEchoPrompt(server, name, prompt)
+		}
+		ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return server }, nil))
+		t.Cleanup(ts.Close)
+		return ts
+	}
+	alpha := newBackend("alpha", "summarize", "review")
+	beta := newBackend("beta", "summarize", "explain")
+
+	cfg := &config.Config{
+		Groups: []config.Group{
+			{
+				Name: "writers",
+				Backends: map[string]config.Backend{
+					"alpha": {Name: "alpha", Transport: "http", Endpoint: alpha.URL},
+					"beta":  {Name: "beta", Transport: "http", Endpoint: beta.URL},
+				},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	ctx := context.Background()
+	if err := gateway.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+	if !gateway.GetCapabilities().Prompts {
+		t.Fatal("Gateway should have the prompts capability")
+	}
+
+	session := connectTestClient(t, gateway.GetServer(), nil)
+	result, err := session.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	var names []string
+	for _, prompt := range result.Prompts {
+		names = append(names, prompt.Name)
+		if len(prompt.Arguments) != 1 || prompt.Arguments[0].Name != "topic" {
+			t.Errorf("Expected prompt %s to keep its arguments, got %+v", prompt.Name, prompt.Arguments)
+		}
+	}
+	slices.Sort(names)
+	// The colliding prompt of the second backend is listed under its qualified name
+	if expected := []string{"beta.summarize", "explain", "review", "summarize"}; !slices.Equal(names, expected) {
+		t.Errorf("Expected prompts %v, got %v", expected, names)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"summarize", "alpha: gateways"},
+		{"beta.summarize", "beta: gateways"},
+		{"explain", "beta: gateways"},
+	}
+	for _, tt := range tests {
+		prompt, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: tt.name, Arguments: map[string]string{"topic": "gateways"}})
+		if err != nil {
+			t.Fatalf("GetPrompt(%s) failed: %v", tt.name, err)
+		}
+		if text, ok := prompt.Messages[0].Content.(*mcp.TextContent); !ok || text.Text != tt.expected {
+			t.Errorf("Expected %s to return %q, got %v", tt.name, tt.expected, prompt.Messages[0].Content)
+		}
+	}
+
+	if _, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: "missing"}); err == nil {
+		t.Error("Expected getting an unknown prompt to fail")
+	}
+}
+
+func TestRoutingTable_SetBackendPrompts(t *testing.T) {
+	rt := NewRoutingTable()
+	info := BackendInfo{Name: "writer", Group: "docs"}
+
+	prompts := []*mcp.Prompt{{Name: "summarize", Description: "Summarizes"}}
+	if !rt.setBackendPrompts(info, config.NamespaceBackend, prompts) {
+		t.Error("Expected new prompts to be reported as changed")
+	}
+	if rt.setBackendPrompts(info, config.NamespaceBackend, []*mcp.Prompt{{Name: "summarize", Description: "Summarizes"}}) {
+		t.Error("Expected identical prompts to be reported as unchanged")
+	}
+
+	route, exists := rt.ResolvePrompt("writer.summarize")
+	if !exists || route.Backend != "writer" || route.Prompt != "summarize" {
+		t.Errorf("Expected writer.summarize to route to summarize on writer, got %+v", route)
+	}
+	if listed := rt.GetPrompts(); len(listed) != 1 || listed[0].Name != "writer.summarize" || listed[0].Description != "Summarizes" {
+		t.Errorf("Expected the prompt under its namespaced name, got %+v", listed)
+	}
+
+	if !rt.setBackendPrompts(info, config.NamespaceBackend, nil) {
+		t.Error("Expected removed prompts to be reported as changed")
+	}
+	if _, exists := rt.ResolvePrompt("writer.summarize"); exists {
+		t.Error("Expected removed prompts to no longer route")
+	}
+}
@@ -0,0 +1,102 @@
+package gateway
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// refreshTimeout bounds a single re-discovery run
+const refreshTimeout = 30 * time.Second
+
+// capabilityRefresher re-discovers backend capabilities periodically and
+// whenever a backend reports that one of its lists changed
+type capabilityRefresher struct {
+	gateway  *Gateway
+	interval time.Duration
+	triggers chan string // names of backends to refresh
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// newCapabilityRefresher creates a refresher for gateway. A zero interval
+// disables periodic refreshes; list changes are still picked up.
+func newCapabilityRefresher(gateway *Gateway, interval time.Duration) *capabilityRefresher {
+	return &capabilityRefresher{
+		gateway:  gateway,
+		interval: interval,
+		triggers: make(chan string, 64),
+	}
+}
+
+// start runs the refresh loop in the background
+func (r *capabilityRefresher) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(ctx)
+	}()
+}
+
+// stop ends the refresh loop and waits for a running refresh to finish
+func (r *capabilityRefresher) stop() {
+	if r.cancel != nil {
+		r.cancel()
+		r.wg.Wait()
+	}
+}
+
+// handleListChanged is registered with the NotificationRouter. It runs on
+// the backend's reader goroutine, so the refresh itself happens elsewhere.
+func (r *capabilityRefresher) handleListChanged(backendName, method string) {
+	select {
+	case r.triggers <- backendName:
+	default:
+		slog.Warn("Dropping refresh request: too many pending", "backend", backendName)
+	}
+}
+
+func (r *capabilityRefresher) run(ctx context.Context) {
+	var tick <-chan time.Time
+	if r.interval > 0 {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			refreshCtx, cancel := context.WithTimeout(ctx, refreshTimeout)
+			r.gateway.refreshAll(refreshCtx)
+			cancel()
+		case backendName := <-r.triggers:
+			// Coalesce a burst of notifications into one refresh per backend
+			pending := map[string]bool{backendName: true}
+			for drained := false; !drained; {
+				select {
+				case name := <-r.triggers:
+					pending[name] = true
+				default:
+					drained = true
+				}
+			}
+
+			for name := range pending {
+				backend, exists := r.gateway.backendManager.GetBackend(name)
+				if !exists {
+					continue
+				}
+				refreshCtx, cancel := context.WithTimeout(ctx, refreshTimeout)
+				r.gateway.refreshBackend(refreshCtx, backend, nil)
+				cancel()
+			}
+		}
+	}
+}
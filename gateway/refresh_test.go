@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// addGreetTool registers a trivial tool on an SDK server
+func addGreetTool(server *mcp.Server, name string) {
+	mcp.AddTool(server, &mcp.Tool{Name: name, Description: "Greets"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+}
+
+func TestGateway_RefreshesOnListChanged(t *testing.T) {
+	backendServer := mcp.NewServer(&mcp.Implementation{Name: "changing", Version: "1.0.0"}, nil)
+	addGreetTool(backendServer, "greet")
+	ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return backendServer }, nil))
+	defer ts.Close()
+
+	cfg := &config.Config{
+		Groups: []config.Group{
+			{
+				Name: "test-group",
+				Backends: map[string]config.Backend{
+					"changing-backend": {
+						Name:      "changing-backend",
+						Transport: "http",
+						Endpoint:  ts.URL,
+					},
+				},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	if err := gateway.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+
+	listChanged := make(chan struct{}, 10)
+	connectTestClient(t, gateway.GetServer(), &mcp.ClientOptions{
+		ToolListChangedHandler: func(ctx context.Context, req *mcp.ToolListChangedRequest) {
+			listChanged <- struct{}{}
+		},
+	})
+
+	// The backend announces the change on its event stream
+	addGreetTool(backendServer, "farewell")
+	backendServer.RemoveTools("greet")
+
+	routingTable := gateway.GetRoutingTable()
+	refreshed := waitFor(t, 5*time.Second, func() bool {
+		_, added := routingTable.FindToolBackend("farewell")
+		_, stale := routingTable.FindToolBackend("greet")
+		return added && !stale
+	})
+	if !refreshed {
+		t.Fatalf("Routing table was not refreshed, tools: %v", routingTable.GetAllTools())
+	}
+
+	select {
+	case <-listChanged:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Client did not receive a tools list_changed notification")
+	}
+}
+
+func TestGateway_RefreshesPeriodically(t *testing.T) {
+	toolsFile := filepath.Join(t.TempDir(), "tools")
+	if err := os.WriteFile(toolsFile, []byte("echo"), 0644); err != nil {
+		t.Fatalf("Failed to write tools file: %v", err)
+	}
+
+	backendCfg := helperBackendConfig("stdio-backend")
+	backendCfg.Env[stdioHelperToolsFileEnv] = toolsFile
+
+	cfg := &config.Config{
+		Gateway: config.GatewayConfig{RefreshInterval: 20 * time.Millisecond},
+		Groups: []config.Group{
+			{
+				Name:     "test-group",
+				Backends: map[string]config.Backend{"stdio-backend": backendCfg},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	if err := gateway.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+
+	// The helper reads the tools file on every tools/list
+	if err := os.WriteFile(toolsFile, []byte("echo\nreverse"), 0644); err != nil {
+		t.Fatalf("Failed to write tools file: %v", err)
+	}
+
+	refreshed := waitFor(t, 5*time.Second, func() bool {
+		_, exists := gateway.GetRoutingTable().FindToolBackend("reverse")
+		return exists
+	})
+	if !refreshed {
+		t.Fatal("Routing table was not refreshed periodically")
+	}
+}
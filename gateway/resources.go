@@ -0,0 +1,147 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ResourceHandler serves the resources of the backends behind an endpoint.
+// Reads and subscriptions are routed to the backend that listed the resource.
+type ResourceHandler struct {
+	backendManager *BackendManager
+	routingTable   *RoutingTable
+	authorizer     *Authorizer          // nil if clients are not authorized per group
+	subscriptions  *subscriptionManager // nil if subscriptions are not supported
+}
+
+// NewResourceHandler creates a new resource handler
+func NewResourceHandler(backendManager *BackendManager, routingTable *RoutingTable) *ResourceHandler {
+	return &ResourceHandler{
+		backendManager: backendManager,
+		routingTable:   routingTable,
+	}
+}
+
+// HandleReadResource implements resources/read by reading the resource from
+// the backend that lists it or whose resource template matches it
+func (rh *ResourceHandler) HandleReadResource(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := request.Params.URI
+
+	backendName, exists := rh.routingTable.FindResourceBackend(uri)
+	if !exists {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	backend, exists := rh.backendManager.GetBackend(backendName)
+	if !exists {
+		return nil, fmt.Errorf("backend '%s' not available", backendName)
+	}
+
+	if err := rh.authorize(request.Extra, request.Session, "read", uri, backend.GetInfo().Group); err != nil {
+		return nil, err
+	}
+
+	response, err := backend.SendRequest(ctx, "resources/read", &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource from backend: %w", err)
+	}
+
+	var result mcp.ReadResourceResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse resource contents: %w", err)
+	}
+	return &result, nil
+}
+
+// HandleSubscribe implements resources/subscribe by subscribing the session
+// to the resource on the backend that owns it. The SDK records the session's
+// subscription once this returns without error.
+func (rh *ResourceHandler) HandleSubscribe(ctx context.Context, request *mcp.SubscribeRequest) error {
+	uri := request.Params.URI
+
+	backendName, exists := rh.routingTable.FindResourceBackend(uri)
+	if !exists {
+		return mcp.ResourceNotFoundError(uri)
+	}
+
+	backend, exists := rh.backendManager.GetBackend(backendName)
+	if !exists {
+		return fmt.Errorf("backend '%s' not available", backendName)
+	}
+
+	if err := rh.authorize(request.Extra, request.Session, "subscribe to", uri, backend.GetInfo().Group); err != nil {
+		return err
+	}
+
+	return rh.subscriptions.subscribe(ctx, backendName, uri, request.Session)
+}
+
+// HandleUnsubscribe implements resources/unsubscribe by dropping the
+// session's subscription to the resource
+func (rh *ResourceHandler) HandleUnsubscribe(ctx context.Context, request *mcp.UnsubscribeRequest) error {
+	return rh.subscriptions.unsubscribe(ctx, request.Params.URI, request.Session)
+}
+
+// visible reports whether a resource is listed to the client that sent request
+func (rh *ResourceHandler) visible(request mcp.Request, uri string) bool {
+	backendName, exists := rh.routingTable.FindResourceBackend(uri)
+	return exists && backendListed(rh.backendManager, rh.authorizer, request, backendName)
+}
+
+// backendListed reports whether the tools, resources and prompts of a
+// backend are listed to the client that sent request: the backend must be healthy and
+// belong to a group the client may access
+func backendListed(backendManager *BackendManager, authorizer *Authorizer, request mcp.Request, backendName string) bool {
+	backend, exists := backendManager.GetBackend(backendName)
+	if !exists || !backend.IsHealthy() {
+		return false
+	}
+	return authorizer == nil || authorizer.Allowed(extraIdentity(request.GetExtra()), backend.GetInfo().Group)
+}
+
+// authorize checks that the client of a session may access a resource of
+// the given group. action describes the access in errors.
+func (rh *ResourceHandler) authorize(extra *mcp.RequestExtra, session *mcp.ServerSession, action, uri, group string) error {
+	if rh.authorizer == nil {
+		return nil
+	}
+
+	return rh.authorizer.Authorize(extraIdentity(extra), group, fmt.Sprintf("%s resource '%s'", action, uri), sessionID(session))
+}
+
+// filterResources is a receiving middleware that hides the resources and
+// resource templates of unhealthy backends and of groups the client may not
+// access from resources/list and resources/templates/list results. The SDK
+// cuts the page before it is filtered, so a page may hold fewer items than
+// the page size, or none, while its cursor still leads to the rest.
+func (rh *ResourceHandler) filterResources(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, request mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, request)
+		if err != nil {
+			return result, err
+		}
+
+		switch list := result.(type) {
+		case *mcp.ListResourcesResult:
+			visible := make([]*mcp.Resource, 0, len(list.Resources))
+			for _, resource := range list.Resources {
+				if rh.visible(request, resource.URI) {
+					visible = append(visible, resource)
+				}
+			}
+			list.Resources = visible
+		case *mcp.ListResourceTemplatesResult:
+			visible := make([]*mcp.ResourceTemplate, 0, len(list.ResourceTemplates))
+			for _, template := range list.ResourceTemplates {
+				if backendName, exists := rh.routingTable.FindResourceTemplateBackend(template.URITemplate); exists && backendListed(rh.backendManager, rh.authorizer, request, backendName) {
+					visible = append(visible, template)
+				}
+			}
+			list.ResourceTemplates = visible
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,190 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// addTextResource adds a resource whose contents are text to server
+func addTextResource(server *mcp.Server, uri, text string) {
+	server.AddResource(&mcp.Resource{URI: uri, Name: uri, MIMEType: "text/plain"}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: req.Params.URI, MIMEType: "text/plain", Text: text}},
+		}, nil
+	})
+}
+
+// listResourceURIs lists the URIs of the resources served by server
+func listResourceURIs(t *testing.T, server *mcp.Server) []string {
+	t.Helper()
+
+	session := connectTestClient(t, server, nil)
+	result, err := session.ListResources(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+
+	uris := make([]string, 0, len(result.Resources))
+	for _, resource := range result.Resources {
+		uris = append(uris, resource.URI)
+	}
+	slices.Sort(uris)
+	return uris
+}
+
+func TestGateway_AggregatesResources(t *testing.T) {
+	newBackend := func(name string, uris ...string) (*mcp.Server, *httptest.Server) {
+		server := mcp.NewServer(&mcp.Implementation{Name: name, Version: "1.0.0"}, nil)
+		for _, uri := range uris {
+			addTextResource(server, uri, "contents of "+uri)
+		}
+		ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return server }, nil))
+		t.Cleanup(ts.Close)
+		return server, ts
+	}
+	docsServer, docsBackend := newBackend("docs", "docs://guide", "docs://faq")
+	_, schemaBackend := newBackend("schemas", "schema://users")
+
+	cfg := &config.Config{
+		Groups: []config.Group{
+			{
+				Name: "docs",
+				Backends: map[string]config.Backend{
+					"docs-backend": {Name: "docs-backend", Transport: "http", Endpoint: docsBackend.URL},
+				},
+			},
+			{
+				Name: "data",
+				Backends: map[string]config.Backend{
+					"schema-backend": {Name: "schema-backend", Transport: "http", Endpoint: schemaBackend.URL},
+				},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	ctx := context.Background()
+	if err := gateway.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+	if !gateway.GetCapabilities().Resources {
+		t.Fatal("Gateway should have the resources capability")
+	}
+
+	if uris := listResourceURIs(t, gateway.GetServer()); !slices.Equal(uris, []string{"docs://faq", "docs://guide", "schema://users"}) {
+		t.Errorf("Expected the resources of every backend, got %v", uris)
+	}
+	if uris := listResourceURIs(t, gateway.GetGroupServer("data")); !slices.Equal(uris, []string{"schema://users"}) {
+		t.Errorf("Expected only the data group's resources, got %v", uris)
+	}
+
+	// Reads are routed to the backend that listed the resource
+	session := connectTestClient(t, gateway.GetServer(), nil)
+	for _, uri := range []string{"docs://guide", "schema://users"} {
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			t.Fatalf("ReadResource(%s) failed: %v", uri, err)
+		}
+		if len(result.Contents) != 1 || result.Contents[0].Text != "contents of "+uri {
+			t.Errorf("Unexpected contents of %s: %+v", uri, result.Contents)
+		}
+	}
+	if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "docs://missing"}); err == nil {
+		t.Error("Expected reading an unknown resource to fail")
+	}
+
+	// Resources the backend adds later are registered after it reports the change
+	addTextResource(docsServer, "docs://changelog", "changes")
+	deadline := time.Now().Add(5 * time.Second)
+	for !slices.Contains(listResourceURIs(t, gateway.GetServer()), "docs://changelog") {
+		if time.Now().After(deadline) {
+			t.Fatal("Resource added by the backend was not registered")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// Resources of unhealthy backends are not listed
+	backend, _ := gateway.GetBackendManager().GetBackend("schema-backend")
+	backend.SetHealthy(false)
+	if uris := listResourceURIs(t, gateway.GetServer()); slices.Contains(uris, "schema://users") {
+		t.Errorf("Resources of unhealthy backends should be hidden, got %v", uris)
+	}
+}
+
+func TestRoutingTable_SetBackendResources(t *testing.T) {
+	rt := NewRoutingTable()
+
+	resources := []*mcp.Resource{{URI: "docs://guide", Name: "Guide"}}
+	if !rt.setBackendResources("docs", resources) {
+		t.Error("Expected new resources to be reported as changed")
+	}
+	if rt.setBackendResources("docs", []*mcp.Resource{{URI: "docs://guide", Name: "Guide"}}) {
+		t.Error("Expected identical resources to be reported as unchanged")
+	}
+	if !rt.setBackendResources("docs", []*mcp.Resource{{URI: "docs://guide", Name: "User guide"}}) {
+		t.Error("Expected a changed description to be reported as changed")
+	}
+
+	if backend, exists := rt.FindResourceBackend("docs://guide"); !exists || backend != "docs" {
+		t.Errorf("Expected docs://guide to route to docs, got %q", backend)
+	}
+	if listed := rt.GetResources(); len(listed) != 1 || listed[0].Name != "User guide" {
+		t.Errorf("Expected the latest description, got %+v", listed)
+	}
+
+	if !rt.setBackendResources("docs", nil) {
+		t.Error("Expected removed resources to be reported as changed")
+	}
+	if listed := rt.GetResources(); len(listed) != 0 {
+		t.Errorf("Expected no resources, got %+v", listed)
+	}
+}
+
+func TestRoutingTable_ResourceCollisions(t *testing.T) {
+	rt := NewRoutingTable()
+	listing := func(backendName string) []*mcp.Resource {
+		return []*mcp.Resource{{URI: "docs://shared", Name: "Shared of " + backendName}}
+	}
+
+	// The owner does not depend on discovery order
+	rt.setBackendResources("docs-b", listing("docs-b"))
+	rt.setBackendResources("docs-a", listing("docs-a"))
+	for round := 0; round < 3; round++ {
+		for _, backendName := range []string{"docs-b", "docs-a"} {
+			if rt.setBackendResources(backendName, listing(backendName)) {
+				t.Errorf("Round %d: expected refreshing %s to be reported as unchanged", round, backendName)
+			}
+		}
+		if backend, _ := rt.FindResourceBackend("docs://shared"); backend != "docs-a" {
+			t.Errorf("Round %d: expected docs://shared to route to docs-a, got %q", round, backend)
+		}
+	}
+	if listed := rt.GetResources(); len(listed) != 1 || listed[0].Name != "Shared of docs-a" {
+		t.Errorf("Expected the owner's listing, got %+v", listed)
+	}
+
+	// A change of the other backend's listing is not visible to clients
+	if rt.setBackendResources("docs-b", []*mcp.Resource{{URI: "docs://shared", Name: "Renamed"}}) {
+		t.Error("Expected a change hidden by the owner to be reported as unchanged")
+	}
+
+	// The other backend takes over once the owner stops listing the URI
+	if !rt.setBackendResources("docs-a", nil) {
+		t.Error("Expected the change of owner to be reported as changed")
+	}
+	if backend, _ := rt.FindResourceBackend("docs://shared"); backend != "docs-b" {
+		t.Errorf("Expected docs://shared to route to docs-b, got %q", backend)
+	}
+}
@@ -0,0 +1,74 @@
+package gateway
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// sseEvent is a single Server-Sent Event
+type sseEvent struct {
+	Event string
+	Data  string
+	ID    string
+}
+
+// sseReader reads Server-Sent Events from a stream
+type sseReader struct {
+	reader *bufio.Reader
+}
+
+// newSSEReader creates a reader for the text/event-stream body r
+func newSSEReader(r io.Reader) *sseReader {
+	return &sseReader{reader: bufio.NewReader(r)}
+}
+
+// next returns the next event with a non-empty data field. It returns
+// io.EOF when the stream ends.
+func (s *sseReader) next() (*sseEvent, error) {
+	event := &sseEvent{}
+	var data []string
+
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		// A blank line dispatches the event
+		if line == "" {
+			if event.Data = strings.Join(data, "\n"); event.Data != "" {
+				return event, nil
+			}
+			event = &sseEvent{}
+			data = nil
+			continue
+		}
+
+		// Lines starting with a colon are comments
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			event.ID = value
+		}
+
+		// A stream ending without a trailing blank line still delivers the event
+		if err == io.EOF {
+			if event.Data = strings.Join(data, "\n"); event.Data != "" {
+				return event, nil
+			}
+			return nil, io.EOF
+		}
+	}
+}
@@ -0,0 +1,475 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// Reconnect backoff for a dropped event stream
+const (
+	sseInitialBackoff = time.Second
+	sseMaxBackoff     = 30 * time.Second
+)
+
+// SSEBackend implements Backend interface for the legacy HTTP+SSE transport.
+// Responses arrive on a long-lived GET event stream and are correlated with
+// requests, which are POSTed to the endpoint announced by the server. When
+// the stream drops, it is reopened with backoff and initialized again.
+type SSEBackend struct {
+	messageDispatcher
+	restartNotifier
+	info            BackendInfo
+	config          config.Backend
+	client          *http.Client
+	streamClient    *http.Client
+	endpoint        string
+	messageEndpoint string
+	healthy         bool
+	mu              sync.RWMutex
+	connectMu       sync.Mutex
+	pending         *pendingRequests
+	streamCancel    context.CancelFunc
+	done            chan struct{}
+	streamErr       error
+	initParams      interface{}
+	closed          bool
+	closeCtx        context.Context
+	closeFunc       context.CancelFunc
+}
+
+// NewSSEBackend creates a legacy SSE backend. The event stream is opened by
+// Initialize, which waits for the server's endpoint event to learn where
+// messages are POSTed.
+func NewSSEBackend(cfg config.Backend, groupName string) *SSEBackend {
+	closeCtx, closeFunc := context.WithCancel(context.Background())
+	return &SSEBackend{
+		info: BackendInfo{
+			Name:      cfg.Name,
+			Transport: "sse",
+			Group:     groupName,
+		},
+		config:   cfg,
+		endpoint: cfg.Endpoint,
+		// A POST is only acknowledged, its response arrives on the event
+		// stream; both are bounded by the request's context
+		client: &http.Client{},
+		// The event stream carries every response of the session
+		streamClient: &http.Client{},
+		healthy:      true,
+		pending:      newPendingRequests(),
+		closeCtx:     closeCtx,
+		closeFunc:    closeFunc,
+	}
+}
+
+func (b *SSEBackend) Initialize(ctx context.Context, req interface{}) (*mcp.InitializeResult, error) {
+	ctx, cancel := withRequestTimeout(ctx, b.RequestTimeout())
+	defer cancel()
+
+	b.mu.Lock()
+	b.initParams = req
+	b.mu.Unlock()
+
+	if err := b.connect(ctx); err != nil {
+		b.SetHealthy(false)
+		return nil, err
+	}
+
+	result, err := b.initialize(ctx, req)
+	if err != nil {
+		b.SetHealthy(false)
+		return nil, err
+	}
+
+	b.SetHealthy(true)
+	return result, nil
+}
+
+// initialize performs the initialize handshake on the current event stream
+func (b *SSEBackend) initialize(ctx context.Context, req interface{}) (*mcp.InitializeResult, error) {
+	response, err := b.sendJSONRPC(ctx, "initialize", req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *mcp.InitializeResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal initialize response: %w", err)
+	}
+
+	if err := b.notify(ctx, "notifications/initialized", struct{}{}); err != nil {
+		return nil, fmt.Errorf("failed to send initialized notification: %w", err)
+	}
+
+	return result, nil
+}
+
+// connect opens the event stream and waits for the server to announce the
+// endpoint that messages must be POSTed to. It is a no-op while a stream is open.
+func (b *SSEBackend) connect(ctx context.Context) error {
+	b.connectMu.Lock()
+	defer b.connectMu.Unlock()
+
+	b.mu.RLock()
+	done, closed := b.done, b.closed
+	b.mu.RUnlock()
+
+	if closed {
+		return fmt.Errorf("backend %s is closed", b.info.Name)
+	}
+	if done != nil {
+		select {
+		case <-done:
+			// The previous stream ended; open a new one
+		default:
+			return nil // Already connected
+		}
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+
+	httpReq, err := http.NewRequestWithContext(streamCtx, "GET", b.endpoint, nil)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+	for key, value := range b.config.Headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := b.streamClient.Do(httpReq)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to open event stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || !isEventStream(resp) {
+		_ = resp.Body.Close()
+		cancel()
+		return fmt.Errorf("event stream request failed with status %d", resp.StatusCode)
+	}
+
+	// Wait for the endpoint event, giving up when ctx expires
+	events := newSSEReader(resp.Body)
+	type endpointResult struct {
+		url string
+		err error
+	}
+	endpointCh := make(chan endpointResult, 1)
+	go func() {
+		event, err := events.next()
+		switch {
+		case err != nil:
+			endpointCh <- endpointResult{err: fmt.Errorf("event stream ended before endpoint event: %w", err)}
+		case event.Event != "endpoint":
+			endpointCh <- endpointResult{err: fmt.Errorf("expected endpoint event, got %q", event.Event)}
+		default:
+			messageURL, err := resolveMessageEndpoint(b.endpoint, event.Data)
+			endpointCh <- endpointResult{url: messageURL, err: err}
+		}
+	}()
+
+	var result endpointResult
+	select {
+	case result = <-endpointCh:
+	case <-ctx.Done():
+		result.err = ctx.Err()
+	}
+	if result.err != nil {
+		_ = resp.Body.Close()
+		cancel()
+		return result.err
+	}
+
+	done = make(chan struct{})
+
+	b.mu.Lock()
+	b.messageEndpoint = result.url
+	b.streamCancel = cancel
+	b.streamErr = nil
+	b.done = done
+	b.mu.Unlock()
+
+	go b.readLoop(resp.Body, events, done)
+
+	return nil
+}
+
+// resolveMessageEndpoint resolves the announced endpoint against the stream URL
+func resolveMessageEndpoint(streamURL, announced string) (string, error) {
+	base, err := url.Parse(streamURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid stream URL: %w", err)
+	}
+	ref, err := url.Parse(announced)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint announced by server: %w", err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// readLoop reads messages from the event stream and hands each response to
+// the request waiting for its id. It runs until the stream ends; a stream
+// that ended on its own is reopened.
+func (b *SSEBackend) readLoop(body io.ReadCloser, events *sseReader, done chan struct{}) {
+	lost := false
+	// Runs last, once done is closed, so that connect opens a new stream
+	defer func() {
+		if lost {
+			go b.reconnectLoop()
+		}
+	}()
+	defer close(done)
+	defer func() { _ = body.Close() }()
+
+	for {
+		event, err := events.next()
+		if err != nil {
+			// Streams dropped or closed by us are already accounted for
+			b.mu.Lock()
+			lost = b.done == done && !b.closed
+			b.streamErr = err
+			b.healthy = false
+			b.mu.Unlock()
+			b.pending.failAll()
+			if lost {
+				slog.Warn("Event stream lost", "backend", b.info.Name, "error", err)
+			}
+			return
+		}
+
+		if event.Event != "" && event.Event != "message" {
+			continue
+		}
+
+		messages, err := decodeJSONRPCMessages([]byte(event.Data))
+		if err != nil {
+			slog.Warn("Invalid message in event stream", "backend", b.info.Name, "error", err)
+			continue
+		}
+
+		for i := range messages {
+			if messages[i].isResponse() {
+				if !b.pending.deliver(&messages[i]) {
+					slog.Warn("Dropping response with unknown id", "backend", b.info.Name, "jsonrpc_id", messages[i].idKey())
+				}
+				continue
+			}
+			b.dispatch(context.Background(), b.info.Name, &messages[i], b.reply)
+		}
+	}
+}
+
+// reconnectLoop reopens the event stream with exponential backoff and
+// repeats the initialize handshake, since the new stream is a new session
+func (b *SSEBackend) reconnectLoop() {
+	backoff := sseInitialBackoff
+
+	for {
+		select {
+		case <-b.closeCtx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		b.mu.RLock()
+		initParams := b.initParams
+		b.mu.RUnlock()
+
+		ctx, cancel := context.WithTimeout(b.closeCtx, b.RequestTimeout())
+		var result *mcp.InitializeResult
+		err := b.connect(ctx)
+		if err == nil {
+			result, err = b.initialize(ctx, initParams)
+		}
+		cancel()
+
+		if err == nil {
+			slog.Info("Event stream reconnected", "backend", b.info.Name)
+			b.SetHealthy(true)
+
+			ctx, cancel := context.WithTimeout(b.closeCtx, restartHandlerTimeout)
+			b.notifyRestart(ctx, result)
+			cancel()
+			return
+		}
+
+		slog.Warn("Event stream reconnect failed", "backend", b.info.Name, "error", err)
+		b.dropStream()
+
+		backoff *= 2
+		if backoff > sseMaxBackoff {
+			backoff = sseMaxBackoff
+		}
+	}
+}
+
+// dropStream closes the current event stream without triggering a reconnect
+func (b *SSEBackend) dropStream() {
+	b.mu.Lock()
+	cancel := b.streamCancel
+	b.streamCancel = nil
+	b.done = nil
+	b.messageEndpoint = ""
+	b.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (b *SSEBackend) SendRequest(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
+	ctx, cancel := withRequestTimeout(ctx, b.RequestTimeout())
+	defer cancel()
+	return b.sendJSONRPC(ctx, method, params)
+}
+
+// RequestTimeout implements TimeoutProvider
+func (b *SSEBackend) RequestTimeout() time.Duration {
+	return requestTimeout(b.config)
+}
+
+func (b *SSEBackend) sendJSONRPC(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
+	id, responseCh := b.pending.register()
+
+	jsonData, err := json.Marshal(newJSONRPCRequest(id, method, params))
+	if err != nil {
+		b.pending.remove(id)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	if err := b.post(ctx, jsonData); err != nil {
+		b.pending.remove(id)
+		if ctx.Err() != nil {
+			cancelRequest(b.info.Name, b.reply, id, method, ctx.Err())
+			return nil, ctx.Err()
+		}
+		b.SetHealthy(false)
+		return nil, err
+	}
+
+	// Wait for the response to arrive on the event stream
+	select {
+	case response, ok := <-responseCh:
+		if !ok {
+			return nil, fmt.Errorf("event stream closed before response: %w", b.streamError())
+		}
+		result, err := response.resultOrError()
+		if err != nil {
+			return nil, err
+		}
+		b.SetHealthy(true)
+		return result, nil
+	case <-ctx.Done():
+		b.pending.remove(id)
+		cancelRequest(b.info.Name, b.reply, id, method, ctx.Err())
+		return nil, ctx.Err()
+	}
+}
+
+// notify sends a JSON-RPC notification to the backend
+func (b *SSEBackend) notify(ctx context.Context, method string, params interface{}) error {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	return b.post(ctx, jsonData)
+}
+
+// post sends a JSON-RPC payload to the message endpoint announced by the server
+func (b *SSEBackend) post(ctx context.Context, data []byte) error {
+	b.mu.RLock()
+	messageEndpoint := b.messageEndpoint
+	b.mu.RUnlock()
+
+	if messageEndpoint == "" {
+		return fmt.Errorf("backend %s is not connected", b.info.Name)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", messageEndpoint, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// The configured headers go on every POST as on the event stream
+	for key, value := range b.config.Headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := b.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP request failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// reply sends our response to a request initiated by the backend
+func (b *SSEBackend) reply(data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), b.RequestTimeout())
+	defer cancel()
+	return b.post(ctx, data)
+}
+
+// streamError returns the error that ended the event stream
+func (b *SSEBackend) streamError() error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.streamErr == nil {
+		return io.ErrUnexpectedEOF
+	}
+	return b.streamErr
+}
+
+func (b *SSEBackend) GetInfo() BackendInfo {
+	return b.info
+}
+
+func (b *SSEBackend) Close() error {
+	b.closeFunc()
+
+	b.mu.Lock()
+	b.closed = true
+	if b.streamCancel != nil {
+		b.streamCancel()
+		b.streamCancel = nil
+	}
+	b.mu.Unlock()
+
+	b.pending.failAll()
+	return nil
+}
+
+func (b *SSEBackend) IsHealthy() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.healthy
+}
+
+func (b *SSEBackend) SetHealthy(healthy bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.healthy = healthy
+}
@@ -0,0 +1,182 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// newLegacySSETestServer serves an SDK-based MCP server over the HTTP+SSE transport
+func newLegacySSETestServer(t *testing.T) (*httptest.Server, *mcp.Server) {
+	t.Helper()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "legacy-sse", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "greet", Description: "Greets"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		Name string `json:"name"`
+	}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "Hello " + args.Name}}}, nil, nil
+	})
+
+	ts := httptest.NewServer(mcp.NewSSEHandler(func(r *http.Request) *mcp.Server { return server }, nil))
+	t.Cleanup(ts.Close)
+	return ts, server
+}
+
+func TestSSEBackend_InitializeAndCall(t *testing.T) {
+	ts, _ := newLegacySSETestServer(t)
+
+	backend := NewSSEBackend(config.Backend{
+		Name:      "sse-backend",
+		Transport: "sse",
+		Endpoint:  ts.URL,
+	}, "test-group")
+	defer func() { _ = backend.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := backend.Initialize(ctx, testInitParams())
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if result.ServerInfo == nil || result.ServerInfo.Name != "legacy-sse" {
+		t.Errorf("Unexpected server info: %+v", result.ServerInfo)
+	}
+	if !backend.IsHealthy() {
+		t.Error("Backend should be healthy after successful initialization")
+	}
+
+	// Concurrent calls are correlated by id on the shared event stream
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			name := fmt.Sprintf("caller-%d", i)
+			response, err := backend.SendRequest(ctx, "tools/call", map[string]interface{}{
+				"name":      "greet",
+				"arguments": map[string]interface{}{"name": name},
+			})
+			if err != nil {
+				t.Errorf("tools/call %d failed: %v", i, err)
+				return
+			}
+
+			var toolResult mcp.CallToolResult
+			if err := json.Unmarshal(*response, &toolResult); err != nil {
+				t.Errorf("Failed to unmarshal tool result: %v", err)
+				return
+			}
+			if text, ok := toolResult.Content[0].(*mcp.TextContent); !ok || text.Text != "Hello "+name {
+				t.Errorf("Unexpected tool result for %s: %v", name, toolResult.Content)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestSSEBackend_ReconnectsAfterStreamLoss(t *testing.T) {
+	ts, server := newLegacySSETestServer(t)
+
+	backend := NewSSEBackend(config.Backend{
+		Name:      "sse-backend",
+		Transport: "sse",
+		Endpoint:  ts.URL,
+	}, "test-group")
+	defer func() { _ = backend.Close() }()
+
+	restarted := make(chan *mcp.InitializeResult, 1)
+	backend.SetRestartHandler(func(ctx context.Context, result *mcp.InitializeResult) {
+		restarted <- result
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := backend.Initialize(ctx, testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	// The server ends the session, and with it the event stream
+	for session := range server.Sessions() {
+		_ = session.Close()
+	}
+
+	// The backend reopens the stream and repeats the handshake in the background
+	select {
+	case result := <-restarted:
+		if result.ServerInfo == nil || result.ServerInfo.Name != "legacy-sse" {
+			t.Errorf("Unexpected server info after reconnect: %+v", result.ServerInfo)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Backend did not reconnect")
+	}
+	if !backend.IsHealthy() {
+		t.Error("Backend should be healthy after reconnecting")
+	}
+
+	response, err := backend.SendRequest(ctx, "tools/call", map[string]interface{}{
+		"name":      "greet",
+		"arguments": map[string]interface{}{"name": "again"},
+	})
+	if err != nil {
+		t.Fatalf("Call after reconnect failed: %v", err)
+	}
+	var result mcp.CallToolResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		t.Fatalf("Failed to unmarshal tool result: %v", err)
+	}
+	if text, ok := result.Content[0].(*mcp.TextContent); !ok || text.Text != "Hello again" {
+		t.Errorf("Unexpected tool result after reconnect: %v", result.Content)
+	}
+}
+
+func TestSSEBackend_InitializeFailsWithoutStream(t *testing.T) {
+	server := MockHTTPServer(t)
+	defer server.Close()
+
+	backend := NewSSEBackend(config.Backend{
+		Name:      "sse-backend",
+		Transport: "sse",
+		Endpoint:  server.URL,
+	}, "test-group")
+
+	if _, err := backend.Initialize(context.Background(), testInitParams()); err == nil {
+		t.Fatal("Expected error when the endpoint does not serve an event stream")
+	}
+	if backend.IsHealthy() {
+		t.Error("Backend should be unhealthy after failed initialization")
+	}
+}
+
+func TestResolveMessageEndpoint(t *testing.T) {
+	tests := []struct {
+		stream    string
+		announced string
+		expected  string
+	}{
+		{"http://host:8080/sse", "/message?sessionid=1", "http://host:8080/message?sessionid=1"},
+		{"http://host:8080/mcp/sse", "messages?id=2", "http://host:8080/mcp/messages?id=2"},
+		{"http://host:8080/sse", "http://other/post", "http://other/post"},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveMessageEndpoint(tt.stream, tt.announced)
+		if err != nil {
+			t.Errorf("resolveMessageEndpoint(%q, %q) failed: %v", tt.stream, tt.announced, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("resolveMessageEndpoint(%q, %q) = %q, want %q", tt.stream, tt.announced, got, tt.expected)
+		}
+	}
+}
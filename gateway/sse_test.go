@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSSEReader_Next(t *testing.T) {
+	stream := ": keep-alive comment\n" +
+		"event: endpoint\n" +
+		"data: /messages?session=1\n" +
+		"\n" +
+		"id: 42\n" +
+		"data: {\"a\":1,\n" +
+		"data: \"b\":2}\n" +
+		"\n" +
+		"event: message\n" +
+		"data:\n" +
+		"\n" +
+		"data: trailing"
+
+	reader := newSSEReader(strings.NewReader(stream))
+
+	event, err := reader.next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if event.Event != "endpoint" || event.Data != "/messages?session=1" {
+		t.Errorf("Unexpected first event: %+v", event)
+	}
+
+	event, err = reader.next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if event.ID != "42" || event.Data != "{\"a\":1,\n\"b\":2}" {
+		t.Errorf("Unexpected multi-line event: %+v", event)
+	}
+
+	// The empty-data event is skipped
+	event, err = reader.next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if event.Data != "trailing" {
+		t.Errorf("Expected trailing event without blank line, got %+v", event)
+	}
+
+	if _, err := reader.next(); err != io.EOF {
+		t.Errorf("Expected io.EOF at end of stream, got %v", err)
+	}
+}
+
+func TestSSEReader_CRLF(t *testing.T) {
+	reader := newSSEReader(strings.NewReader("event: message\r\ndata: hello\r\n\r\n"))
+
+	event, err := reader.next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if event.Event != "message" || event.Data != "hello" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+}
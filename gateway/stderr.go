@@ -0,0 +1,103 @@
+package gateway
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	// stderrBufferLines is the number of recent stderr lines kept per backend
+	stderrBufferLines = 200
+
+	// stderrMaxLineLength truncates very long lines written to stderr
+	stderrMaxLineLength = 4096
+
+	// stderrErrorLines is the number of stderr lines included in errors
+	stderrErrorLines = 5
+)
+
+// stderrBuffer is a bounded ring buffer of the most recent stderr lines
+type stderrBuffer struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+// newStderrBuffer creates a buffer holding up to size lines
+func newStderrBuffer(size int) *stderrBuffer {
+	return &stderrBuffer{lines: make([]string, size)}
+}
+
+// add appends a line, overwriting the oldest one when the buffer is full
+func (s *stderrBuffer) add(line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lines[s.next] = line
+	s.next = (s.next + 1) % len(s.lines)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// tail returns up to n of the most recent lines, oldest first.
+// A non-positive n returns every buffered line.
+func (s *stderrBuffer) tail(n int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := s.next
+	if s.full {
+		count = len(s.lines)
+	}
+	if n <= 0 || n > count {
+		n = count
+	}
+
+	result := make([]string, 0, n)
+	for i := count - n; i < count; i++ {
+		result = append(result, s.lines[(s.next-count+i+len(s.lines))%len(s.lines)])
+	}
+	return result
+}
+
+// readStderrLines calls handle for each line read from r until r is
+// exhausted. Lines longer than stderrMaxLineLength are truncated rather than
+// stopping the reader, so the child can never block on a full pipe.
+func readStderrLines(r io.Reader, handle func(line string)) {
+	reader := bufio.NewReader(r)
+	var line strings.Builder
+	truncated := false
+
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			if line.Len() > 0 {
+				handle(line.String())
+			}
+			return
+		}
+
+		if !truncated {
+			remaining := stderrMaxLineLength - line.Len()
+			if len(chunk) > remaining {
+				chunk = chunk[:remaining]
+				truncated = true
+			}
+			line.Write(chunk)
+		}
+
+		if !isPrefix {
+			text := line.String()
+			if truncated {
+				text += "..."
+			}
+			handle(text)
+			line.Reset()
+			truncated = false
+		}
+	}
+}
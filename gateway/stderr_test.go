@@ -0,0 +1,110 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStderrBuffer_Tail(t *testing.T) {
+	buffer := newStderrBuffer(3)
+
+	if lines := buffer.tail(0); len(lines) != 0 {
+		t.Errorf("Expected empty buffer, got %v", lines)
+	}
+
+	buffer.add("one")
+	buffer.add("two")
+	if lines := buffer.tail(0); !reflect.DeepEqual(lines, []string{"one", "two"}) {
+		t.Errorf("Unexpected lines before wrapping: %v", lines)
+	}
+
+	// Older lines are overwritten once the buffer is full
+	buffer.add("three")
+	buffer.add("four")
+	if lines := buffer.tail(0); !reflect.DeepEqual(lines, []string{"two", "three", "four"}) {
+		t.Errorf("Unexpected lines after wrapping: %v", lines)
+	}
+	if lines := buffer.tail(2); !reflect.DeepEqual(lines, []string{"three", "four"}) {
+		t.Errorf("Unexpected tail: %v", lines)
+	}
+}
+
+func TestReadStderrLines(t *testing.T) {
+	long := strings.Repeat("x", stderrMaxLineLength+100)
+	input := "first\r\n" + long + "\nlast without newline"
+
+	var lines []string
+	readStderrLines(strings.NewReader(input), func(line string) {
+		lines = append(lines, line)
+	})
+
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	if lines[0] != "first" {
+		t.Errorf("Expected CRLF to be stripped, got %q", lines[0])
+	}
+	if lines[1] != strings.Repeat("x", stderrMaxLineLength)+"..." {
+		t.Errorf("Expected long line to be truncated, got %d bytes", len(lines[1]))
+	}
+	if lines[2] != "last without newline" {
+		t.Errorf("Unexpected last line: %q", lines[2])
+	}
+}
+
+func TestStdioBackend_CapturesStderr(t *testing.T) {
+	backend := newHelperStdioBackend(t, "stdio-backend")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := backend.Initialize(ctx, testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		params := map[string]interface{}{
+			"name":      "echo",
+			"arguments": map[string]interface{}{"stderr": fmt.Sprintf("diagnostic %d", i)},
+		}
+		if _, err := backend.SendRequest(ctx, "tools/call", params); err != nil {
+			t.Fatalf("Call failed: %v", err)
+		}
+	}
+
+	captured := waitFor(t, 5*time.Second, func() bool {
+		return len(backend.Stderr()) == 3
+	})
+	if !captured {
+		t.Fatalf("Expected 3 stderr lines, got %v", backend.Stderr())
+	}
+	if lines := backend.Stderr(); lines[0] != "diagnostic 0" || lines[2] != "diagnostic 2" {
+		t.Errorf("Unexpected stderr lines: %v", lines)
+	}
+}
+
+func TestStdioBackend_FailureIncludesStderr(t *testing.T) {
+	cfg := helperBackendConfig("stdio-backend")
+	cfg.Restart.MaxRestarts = -1
+	backend := newHelperStdioBackendWithConfig(t, cfg)
+
+	if _, err := backend.Initialize(context.Background(), testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	params := map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"exit": true},
+	}
+	_, err := backend.SendRequest(context.Background(), "tools/call", params)
+	if err == nil {
+		t.Fatal("Expected call to fail when the process exits")
+	}
+	if !strings.Contains(err.Error(), "fatal: exiting on request") {
+		t.Errorf("Expected error to include stderr output, got: %v", err)
+	}
+}
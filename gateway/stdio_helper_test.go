@@ -0,0 +1,232 @@
+package gateway
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+const (
+	// stdioHelperEnv switches the test binary into a fake stdio MCP server
+	stdioHelperEnv = "GATEWAY_STDIO_HELPER"
+
+	// stdioHelperCrashFileEnv names a file whose existence makes the helper
+	// exit immediately on startup
+	stdioHelperCrashFileEnv = "GATEWAY_STDIO_HELPER_CRASH_FILE"
+
+	// stdioHelperToolsFileEnv names a file listing the tools the helper
+	// reports, one per line
+	stdioHelperToolsFileEnv = "GATEWAY_STDIO_HELPER_TOOLS_FILE"
+
+	// stdioHelperStartsFileEnv names a file the helper appends a line to
+	// every time it starts
+	stdioHelperStartsFileEnv = "GATEWAY_STDIO_HELPER_STARTS_FILE"
+
+	// stdioHelperSilentEnv makes the helper read requests without ever
+	// answering, like a hung server
+	stdioHelperSilentEnv = "GATEWAY_STDIO_HELPER_SILENT"
+)
+
+// TestMain turns the test binary into a fake stdio MCP server when it is
+// re-executed as a child process by newHelperStdioBackend
+func TestMain(m *testing.M) {
+	if os.Getenv(stdioHelperEnv) == "1" {
+		runStdioHelper()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// newHelperStdioBackend creates a stdio backend backed by the helper process
+func newHelperStdioBackend(t *testing.T, name string) *StdioBackend {
+	t.Helper()
+	return newHelperStdioBackendWithConfig(t, helperBackendConfig(name))
+}
+
+// newHelperStdioBackendWithConfig creates a stdio backend from a helper
+// configuration that the caller has adjusted
+func newHelperStdioBackendWithConfig(t *testing.T, cfg config.Backend) *StdioBackend {
+	t.Helper()
+
+	backend := NewStdioBackend(cfg, "test-group")
+	t.Cleanup(func() { _ = backend.Close() })
+	return backend
+}
+
+// helperBackendConfig returns the configuration of a stdio backend that
+// runs the helper process
+func helperBackendConfig(name string) config.Backend {
+	return config.Backend{
+		Name:      name,
+		Transport: "stdio",
+		Command:   os.Args[0],
+		Env:       map[string]string{stdioHelperEnv: "1"},
+	}
+}
+
+// runStdioHelper answers requests concurrently so responses may be written
+// in a different order than the requests arrived
+func runStdioHelper() {
+	if startsFile := os.Getenv(stdioHelperStartsFileEnv); startsFile != "" {
+		if f, err := os.OpenFile(startsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+			_, _ = fmt.Fprintln(f, "start")
+			_ = f.Close()
+		}
+	}
+	if crashFile := os.Getenv(stdioHelperCrashFileEnv); crashFile != "" {
+		if _, err := os.Stat(crashFile); err == nil {
+			os.Exit(1)
+		}
+	}
+
+	if os.Getenv(stdioHelperSilentEnv) == "1" {
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		return
+	}
+
+	var writeMu sync.Mutex
+	write := func(msg interface{}) {
+		data, _ := json.Marshal(msg)
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, _ = os.Stdout.Write(append(data, '\n'))
+	}
+
+	// Responses to requests the helper sends to the gateway
+	clientResponses := make(chan json.RawMessage, 1)
+
+	// Ids of the requests the gateway cancelled
+	var cancelledMu sync.Mutex
+	var cancelled []string
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		var request struct {
+			ID     *json.RawMessage `json:"id"`
+			Method string           `json:"method"`
+			Params struct {
+				Name      string                 `json:"name"`
+				Arguments map[string]interface{} `json:"arguments"`
+				RequestID json.RawMessage        `json:"requestId"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			continue
+		}
+		if request.ID == nil {
+			if request.Method == "notifications/cancelled" {
+				cancelledMu.Lock()
+				cancelled = append(cancelled, string(request.Params.RequestID))
+				cancelledMu.Unlock()
+			}
+			continue
+		}
+		if request.Method == "" {
+			clientResponses <- append(json.RawMessage(nil), scanner.Bytes()...)
+			continue
+		}
+		if _, ok := request.Params.Arguments["stall_stdin"]; ok {
+			// Stop draining stdin, like a hung process
+			time.Sleep(time.Hour)
+		}
+
+		go func() {
+			response := map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      request.ID,
+			}
+
+			switch request.Method {
+			case "initialize":
+				response["result"] = map[string]interface{}{
+					"protocolVersion": "2024-11-05",
+					"capabilities": map[string]interface{}{
+						"tools": map[string]interface{}{},
+					},
+					"serverInfo": map[string]interface{}{
+						"name":    "stdio-helper",
+						"version": "1.0.0",
+					},
+				}
+			case "tools/list":
+				toolNames := helperToolNames()
+				tools := make([]map[string]interface{}, 0, len(toolNames))
+				for _, name := range toolNames {
+					tools = append(tools, map[string]interface{}{"name": name, "description": "Echoes the value argument"})
+				}
+				response["result"] = map[string]interface{}{"tools": tools}
+			case "tools/call":
+				if line, ok := request.Params.Arguments["stderr"].(string); ok {
+					fmt.Fprintln(os.Stderr, line)
+				}
+				if _, ok := request.Params.Arguments["exit"]; ok {
+					fmt.Fprintln(os.Stderr, "fatal: exiting on request")
+					os.Exit(1)
+				}
+				if line, ok := request.Params.Arguments["stdout"].(string); ok {
+					writeMu.Lock()
+					fmt.Fprintln(os.Stdout, line)
+					writeMu.Unlock()
+				}
+				if delay, ok := request.Params.Arguments["delay_ms"].(float64); ok {
+					time.Sleep(time.Duration(delay) * time.Millisecond)
+				}
+				value, _ := request.Params.Arguments["value"].(string)
+				if notify, ok := request.Params.Arguments["notify"].(string); ok {
+					write(map[string]interface{}{
+						"jsonrpc": "2.0",
+						"method":  "notifications/message",
+						"params":  map[string]interface{}{"level": "info", "data": notify},
+					})
+				}
+				if _, ok := request.Params.Arguments["ping_client"]; ok {
+					write(map[string]interface{}{
+						"jsonrpc": "2.0",
+						"id":      "helper-1",
+						"method":  "ping",
+					})
+					value = string(<-clientResponses)
+				}
+				if _, ok := request.Params.Arguments["cancelled"]; ok {
+					cancelledMu.Lock()
+					value = strings.Join(cancelled, ",")
+					cancelledMu.Unlock()
+				}
+				response["result"] = map[string]interface{}{
+					"content": []map[string]interface{}{
+						{"type": "text", "text": value},
+					},
+				}
+			case "ping":
+				response["result"] = map[string]interface{}{}
+			default:
+				response["error"] = map[string]interface{}{
+					"code":    -32601,
+					"message": "Method not found",
+				}
+			}
+
+			write(response)
+		}()
+	}
+}
+
+// helperToolNames returns the tools the helper reports, read from the tools
+// file on every call so that tests can change them while the helper runs
+func helperToolNames() []string {
+	if toolsFile := os.Getenv(stdioHelperToolsFileEnv); toolsFile != "" {
+		if data, err := os.ReadFile(toolsFile); err == nil {
+			return strings.Fields(string(data))
+		}
+	}
+	return []string{"echo"}
+}
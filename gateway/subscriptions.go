@@ -0,0 +1,220 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// unsubscribeTimeout bounds the unsubscribe request sent to a backend after
+// the last subscribed session ended
+const unsubscribeTimeout = 10 * time.Second
+
+// subscriptionManager keeps the resource subscriptions of the gateway's
+// client sessions. The gateway holds a single subscription per resource on
+// the backend that owns it for as long as any session is subscribed. The
+// SDK delivers updates to the subscribed sessions of each server.
+//
+// Sessions are added and removed under mu; the requests that bring the
+// backend in line are sent outside it, one at a time per resource, so that
+// a slow backend only holds up subscriptions to its own resources.
+type subscriptionManager struct {
+	backendManager *BackendManager
+	mu             sync.Mutex
+	subscriptions  map[string]*resourceSubscription // resource URI -> subscription
+	watched        map[*mcp.ServerSession]bool      // sessions whose end is watched
+}
+
+// resourceSubscription is the gateway's subscription to a resource on a
+// backend. sessions and active are guarded by the manager's mu.
+type resourceSubscription struct {
+	backend  string
+	sessions map[*mcp.ServerSession]bool
+	active   bool          // whether the gateway is subscribed on the backend
+	busy     chan struct{} // holds a token while a request for the resource is in flight
+}
+
+// newSubscriptionManager creates a manager without subscriptions
+func newSubscriptionManager(backendManager *BackendManager) *subscriptionManager {
+	return &subscriptionManager{
+		backendManager: backendManager,
+		subscriptions:  make(map[string]*resourceSubscription),
+		watched:        make(map[*mcp.ServerSession]bool),
+	}
+}
+
+// subscribe subscribes session to a resource of a backend, subscribing the
+// gateway on the backend if no other session is subscribed yet
+func (sm *subscriptionManager) subscribe(ctx context.Context, backendName, uri string, session *mcp.ServerSession) error {
+	sm.mu.Lock()
+	subscription, exists := sm.subscriptions[uri]
+	if !exists {
+		subscription = &resourceSubscription{
+			backend:  backendName,
+			sessions: make(map[*mcp.ServerSession]bool),
+			busy:     make(chan struct{}, 1),
+		}
+		sm.subscriptions[uri] = subscription
+	}
+	subscription.sessions[session] = true
+
+	// Sessions may end without unsubscribing
+	if session != nil && !sm.watched[session] {
+		sm.watched[session] = true
+		go func() {
+			_ = session.Wait()
+			sm.dropSession(session)
+		}()
+	}
+	sm.mu.Unlock()
+
+	if err := sm.reconcile(ctx, uri, subscription); err != nil {
+		sm.mu.Lock()
+		delete(subscription.sessions, session)
+		sm.forget(uri, subscription)
+		sm.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// unsubscribe unsubscribes session from a resource, unsubscribing the
+// gateway on the backend once no session is subscribed any more
+func (sm *subscriptionManager) unsubscribe(ctx context.Context, uri string, session *mcp.ServerSession) error {
+	sm.mu.Lock()
+	subscription, exists := sm.subscriptions[uri]
+	if !exists || !subscription.sessions[session] {
+		sm.mu.Unlock()
+		return nil
+	}
+	delete(subscription.sessions, session)
+	sm.mu.Unlock()
+
+	return sm.reconcile(ctx, uri, subscription)
+}
+
+// reconcile subscribes or unsubscribes the gateway on the backend so that
+// it is subscribed to the resource exactly while sessions are. Requests for
+// the same resource are sent one at a time, so that they reach the backend
+// in the order the sessions came and went.
+func (sm *subscriptionManager) reconcile(ctx context.Context, uri string, subscription *resourceSubscription) error {
+	select {
+	case subscription.busy <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-subscription.busy }()
+
+	sm.mu.Lock()
+	wanted, active := len(subscription.sessions) > 0, subscription.active
+	sm.mu.Unlock()
+
+	var err error
+	switch {
+	case wanted && !active:
+		if err = sm.send(ctx, subscription.backend, "resources/subscribe", uri); err == nil {
+			active = true
+			slog.Info("Subscribed to backend resource", "backend", subscription.backend, "uri", uri)
+		}
+	case !wanted && active:
+		// Even if the request fails, updates no session asked for are dropped
+		active = false
+		if err = sm.send(ctx, subscription.backend, "resources/unsubscribe", uri); err == nil {
+			slog.Info("Unsubscribed from backend resource", "backend", subscription.backend, "uri", uri)
+		}
+	}
+
+	sm.mu.Lock()
+	subscription.active = active
+	sm.forget(uri, subscription)
+	sm.mu.Unlock()
+	return err
+}
+
+// forget removes a subscription no session is waiting for any more. The
+// caller must hold sm.mu.
+func (sm *subscriptionManager) forget(uri string, subscription *resourceSubscription) {
+	if len(subscription.sessions) == 0 && !subscription.active && sm.subscriptions[uri] == subscription {
+		delete(sm.subscriptions, uri)
+	}
+}
+
+// dropSession removes the subscriptions of a session that ended
+func (sm *subscriptionManager) dropSession(session *mcp.ServerSession) {
+	sm.mu.Lock()
+	delete(sm.watched, session)
+	var uris []string
+	for uri, subscription := range sm.subscriptions {
+		if subscription.sessions[session] {
+			uris = append(uris, uri)
+		}
+	}
+	sm.mu.Unlock()
+
+	for _, uri := range uris {
+		ctx, cancel := context.WithTimeout(context.Background(), unsubscribeTimeout)
+		if err := sm.unsubscribe(ctx, uri, session); err != nil {
+			slog.Warn("Failed to unsubscribe from backend resource", "uri", uri, "session_id", sessionID(session), "error", err)
+		}
+		cancel()
+	}
+}
+
+// resubscribe renews the gateway's subscriptions on a backend that was
+// re-initialized and so forgot them
+func (sm *subscriptionManager) resubscribe(ctx context.Context, backendName string) {
+	sm.mu.Lock()
+	renew := make(map[string]*resourceSubscription)
+	for uri, subscription := range sm.subscriptions {
+		if subscription.backend == backendName && subscription.active {
+			renew[uri] = subscription
+		}
+	}
+	sm.mu.Unlock()
+
+	for uri, subscription := range renew {
+		if err := sm.renew(ctx, uri, subscription); err != nil {
+			slog.Warn("Failed to renew resource subscription", "backend", backendName, "uri", uri, "error", err)
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}
+}
+
+// renew sends the subscribe request for a subscription the gateway still
+// holds, unless sessions unsubscribed meanwhile
+func (sm *subscriptionManager) renew(ctx context.Context, uri string, subscription *resourceSubscription) error {
+	select {
+	case subscription.busy <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-subscription.busy }()
+
+	sm.mu.Lock()
+	active := subscription.active
+	sm.mu.Unlock()
+
+	if !active {
+		return nil
+	}
+	return sm.send(ctx, subscription.backend, "resources/subscribe", uri)
+}
+
+// send sends a subscribe or unsubscribe request for a resource to a backend
+func (sm *subscriptionManager) send(ctx context.Context, backendName, method, uri string) error {
+	backend, exists := sm.backendManager.GetBackend(backendName)
+	if !exists {
+		return fmt.Errorf("backend '%s' not available", backendName)
+	}
+
+	if _, err := backend.SendRequest(ctx, method, &mcp.SubscribeParams{URI: uri}); err != nil {
+		return fmt.Errorf("failed to forward %s to backend: %w", method, err)
+	}
+	return nil
+}
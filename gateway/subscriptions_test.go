@@ -0,0 +1,278 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// stalledSubscribeBackend is a backend whose requests block until released
+type stalledSubscribeBackend struct {
+	probeBackend
+	name     string
+	requests chan string
+	release  chan struct{}
+}
+
+func (b *stalledSubscribeBackend) SendRequest(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
+	b.requests <- method
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	raw := json.RawMessage(`{}`)
+	return &raw, nil
+}
+
+func (b *stalledSubscribeBackend) GetInfo() BackendInfo {
+	return BackendInfo{Name: b.name, Transport: "test", Group: "test-group"}
+}
+
+func TestSubscriptionManager_StalledBackendBlocksOnlyItsResources(t *testing.T) {
+	stalled := &stalledSubscribeBackend{name: "stalled", requests: make(chan string, 10), release: make(chan struct{})}
+	quick := &stalledSubscribeBackend{name: "quick", requests: make(chan string, 10), release: make(chan struct{})}
+	close(quick.release)
+	backendManager := NewBackendManager()
+	backendManager.AddBackend(stalled)
+	backendManager.AddBackend(quick)
+	sm := newSubscriptionManager(backendManager)
+
+	// Sessions that never end
+	first, second := &mcp.ServerSession{}, &mcp.ServerSession{}
+	sm.watched[first], sm.watched[second] = true, true
+
+	ctx := context.Background()
+	results := make(chan error, 2)
+	go func() { results <- sm.subscribe(ctx, "stalled", "logs://slow", first) }()
+	select {
+	case <-stalled.requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Subscription was not forwarded to the backend")
+	}
+
+	// Other resources are subscribed and unsubscribed meanwhile
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := sm.subscribe(timeoutCtx, "quick", "logs://fast", first); err != nil {
+		t.Fatalf("Subscribe to another backend was blocked: %v", err)
+	}
+	if err := sm.unsubscribe(timeoutCtx, "logs://fast", first); err != nil {
+		t.Fatalf("Unsubscribe from another backend was blocked: %v", err)
+	}
+
+	// A concurrent first subscriber shares the pending backend subscription
+	go func() { results <- sm.subscribe(ctx, "stalled", "logs://slow", second) }()
+	close(stalled.release)
+	for range 2 {
+		select {
+		case err := <-results:
+			if err != nil {
+				t.Errorf("Subscribe failed: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Subscribe did not return after the backend answered")
+		}
+	}
+	if len(stalled.requests) != 0 {
+		t.Errorf("Expected a single backend subscription, got %d more requests", len(stalled.requests))
+	}
+}
+
+func TestGateway_ForwardsResourceSubscriptions(t *testing.T) {
+	subscribed := make(chan string, 10)
+	unsubscribed := make(chan string, 10)
+	backendServer := mcp.NewServer(&mcp.Implementation{Name: "builds", Version: "1.0.0"}, &mcp.ServerOptions{
+		SubscribeHandler: func(ctx context.Context, req *mcp.SubscribeRequest) error {
+			subscribed <- req.Params.URI
+			return nil
+		},
+		UnsubscribeHandler: func(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+			unsubscribed <- req.Params.URI
+			return nil
+		},
+	})
+	addTextResource(backendServer, "logs://build", "build log")
+	ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return backendServer }, nil))
+	defer ts.Close()
+
+	cfg := &config.Config{
+		Groups: []config.Group{
+			{
+				Name: "ci",
+				Backends: map[string]config.Backend{
+					"builds": {Name: "builds", Transport: "http", Endpoint: ts.URL},
+				},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	ctx := context.Background()
+	if err := gateway.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+
+	connect := func() (*mcp.ClientSession, chan string) {
+		updates := make(chan string, 10)
+		session := connectTestClient(t, gateway.GetServer(), &mcp.ClientOptions{
+			ResourceUpdatedHandler: func(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+				updates <- req.Params.URI
+			},
+		})
+		return session, updates
+	}
+	first, firstUpdates := connect()
+	second, secondUpdates := connect()
+	_, bystanderUpdates := connect()
+
+	if !first.InitializeResult().Capabilities.Resources.Subscribe {
+		t.Fatal("Gateway should offer resource subscriptions")
+	}
+
+	for _, session := range []*mcp.ClientSession{first, second} {
+		if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: "logs://build"}); err != nil {
+			t.Fatalf("Subscribe failed: %v", err)
+		}
+	}
+	if err := first.Subscribe(ctx, &mcp.SubscribeParams{URI: "logs://missing"}); err == nil {
+		t.Error("Expected subscribing to an unknown resource to fail")
+	}
+
+	// The gateway subscribes once on the owning backend
+	select {
+	case uri := <-subscribed:
+		if uri != "logs://build" {
+			t.Errorf("Expected subscription to logs://build, got %s", uri)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Subscription was not forwarded to the backend")
+	}
+
+	// Updates reach only the subscribed sessions
+	if err := backendServer.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "logs://build"}); err != nil {
+		t.Fatalf("ResourceUpdated failed: %v", err)
+	}
+	for _, updates := range []chan string{firstUpdates, secondUpdates} {
+		select {
+		case uri := <-updates:
+			if uri != "logs://build" {
+				t.Errorf("Expected update of logs://build, got %s", uri)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Subscribed session did not receive the update")
+		}
+	}
+	select {
+	case uri := <-bystanderUpdates:
+		t.Errorf("Session without subscription received update of %s", uri)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if len(subscribed) != 0 {
+		t.Errorf("Expected a single backend subscription, got %d more", len(subscribed))
+	}
+
+	// The backend subscription ends with the last subscribed session
+	if err := first.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: "logs://build"}); err != nil {
+		t.Fatalf("Unsubscribe failed: %v", err)
+	}
+	if len(unsubscribed) != 0 {
+		t.Fatal("Backend subscription ended while a session was still subscribed")
+	}
+	_ = second.Close()
+	select {
+	case uri := <-unsubscribed:
+		if uri != "logs://build" {
+			t.Errorf("Expected unsubscription from logs://build, got %s", uri)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Unsubscription was not forwarded after the last session closed")
+	}
+}
+
+func TestGateway_ResubscribesAfterSessionExpiry(t *testing.T) {
+	subscribed := make(chan string, 10)
+	backendServer := mcp.NewServer(&mcp.Implementation{Name: "builds", Version: "1.0.0"}, &mcp.ServerOptions{
+		SubscribeHandler: func(ctx context.Context, req *mcp.SubscribeRequest) error {
+			subscribed <- req.Params.URI
+			return nil
+		},
+		UnsubscribeHandler: func(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+			return nil
+		},
+	})
+	addTextResource(backendServer, "logs://build", "build log")
+	ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return backendServer }, nil))
+	defer ts.Close()
+
+	cfg := &config.Config{
+		Groups: []config.Group{
+			{
+				Name: "ci",
+				Backends: map[string]config.Backend{
+					"builds": {Name: "builds", Transport: "http", Endpoint: ts.URL},
+				},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	ctx := context.Background()
+	if err := gateway.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+
+	updates := make(chan string, 10)
+	session := connectTestClient(t, gateway.GetServer(), &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updates <- req.Params.URI
+		},
+	})
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: "logs://build"}); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	waitForSubscription := func() {
+		t.Helper()
+		select {
+		case <-subscribed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Subscription was not forwarded to the backend")
+		}
+	}
+	waitForSubscription()
+
+	// The backend forgets the gateway's session; the next request starts a
+	// new one, on which the subscription is renewed
+	for backendSession := range backendServer.Sessions() {
+		_ = backendSession.Close()
+	}
+	if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "logs://build"}); err != nil {
+		t.Fatalf("ReadResource after session expiry failed: %v", err)
+	}
+	waitForSubscription()
+
+	if err := backendServer.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "logs://build"}); err != nil {
+		t.Fatalf("ResourceUpdated failed: %v", err)
+	}
+	select {
+	case <-updates:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Update was not delivered after the session expired")
+	}
+}
@@ -0,0 +1,172 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+)
+
+// templateExpression matches the expressions of an RFC 6570 URI template
+var templateExpression = regexp.MustCompile(`\{[^}]*\}`)
+
+// templateRoute routes the URIs matching a resource template to a backend
+type templateRoute struct {
+	backend  string
+	template *mcp.ResourceTemplate
+	pattern  *regexp.Regexp
+	literals int // number of literal characters, higher is more specific
+	vars     int // number of expressions, lower is more specific
+}
+
+// newTemplateRoute compiles a backend's resource template into a route
+func newTemplateRoute(backendName string, template *mcp.ResourceTemplate) (templateRoute, error) {
+	compiled, err := uritemplate.New(template.URITemplate)
+	if err != nil {
+		return templateRoute{}, err
+	}
+
+	expressions := templateExpression.FindAllString(template.URITemplate, -1)
+	return templateRoute{
+		backend:  backendName,
+		template: template,
+		pattern:  compiled.Regexp(),
+		literals: len(template.URITemplate) - len(strings.Join(expressions, "")),
+		vars:     len(expressions),
+	}, nil
+}
+
+// compareSpecificity orders routes so that the most specific template comes
+// first: the one with the most literal characters, then the fewest expressions
+func (r templateRoute) compareSpecificity(other templateRoute) int {
+	if r.literals != other.literals {
+		return other.literals - r.literals
+	}
+	return r.vars - other.vars
+}
+
+// discoverResourceTemplates discovers the resource templates of a backend.
+// It reports whether the set of templates routed to the backend changed.
+func (cd *CapabilityDiscoverer) discoverResourceTemplates(ctx context.Context, backend Backend, supported bool) (bool, error) {
+	var templates []*mcp.ResourceTemplate
+	if supported {
+		listed, err := listAll[*mcp.ResourceTemplate](ctx, backend, "resources/templates/list", "resourceTemplates")
+		if err != nil {
+			return false, fmt.Errorf("failed to list resource templates: %w", err)
+		}
+		templates = listed
+	}
+
+	backendInfo := backend.GetInfo()
+	changed := false
+	for _, rt := range cd.routingTablesFor(backendInfo) {
+		if rt.setBackendResourceTemplates(backendInfo.Name, templates) {
+			changed = true
+		}
+	}
+	if changed {
+		uriTemplates := make([]string, 0, len(templates))
+		for _, template := range templates {
+			uriTemplates = append(uriTemplates, template.URITemplate)
+		}
+		slog.Info("Mapped resource templates", "backend", backendInfo.Name, "templates", uriTemplates)
+	}
+	return changed, nil
+}
+
+// setBackendResourceTemplates replaces the resource templates routed to a
+// backend and rebuilds the template routes. Templates that are not valid
+// URI templates are skipped. It reports whether the backend's templates
+// changed.
+func (rt *RoutingTable) setBackendResourceTemplates(backendName string, templates []*mcp.ResourceTemplate) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	previous, exists := rt.backendTemplates[backendName]
+	if exists && reflect.DeepEqual(previous, templates) {
+		return false
+	}
+	if !exists && len(templates) == 0 {
+		return false
+	}
+
+	if len(templates) == 0 {
+		delete(rt.backendTemplates, backendName)
+	} else {
+		rt.backendTemplates[backendName] = templates
+	}
+	rt.rebuildTemplates()
+	return true
+}
+
+// rebuildTemplates recomputes templateRoutes, most specific first. Backends
+// are visited in name order, so that among equally specific templates the
+// first backend wins. The caller must hold rt.mu.
+func (rt *RoutingTable) rebuildTemplates() {
+	rt.templateRoutes = nil
+	for _, backendName := range slices.Sorted(maps.Keys(rt.backendTemplates)) {
+		for _, template := range rt.backendTemplates[backendName] {
+			route, err := newTemplateRoute(backendName, template)
+			if err != nil {
+				slog.Warn("Skipping invalid resource template", "backend", backendName, "template", template.URITemplate, "error", err)
+				continue
+			}
+			rt.templateRoutes = append(rt.templateRoutes, route)
+		}
+	}
+	slices.SortStableFunc(rt.templateRoutes, templateRoute.compareSpecificity)
+}
+
+// matchTemplate returns the backend whose most specific template matches a
+// concrete resource URI. The caller must hold rt.mu.
+func (rt *RoutingTable) matchTemplate(resourceURI string) (string, bool) {
+	for _, route := range rt.templateRoutes {
+		if route.pattern.MatchString(resourceURI) {
+			return route.backend, true
+		}
+	}
+	return "", false
+}
+
+// FindResourceTemplateBackend finds the backend that URIs matching a
+// resource template are routed to, if no more specific template matches
+func (rt *RoutingTable) FindResourceTemplateBackend(uriTemplate string) (string, bool) {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	for _, route := range rt.templateRoutes {
+		if route.template.URITemplate == uriTemplate {
+			return route.backend, true
+		}
+	}
+	return "", false
+}
+
+// GetResourceTemplates returns the resource templates of all backends,
+// ordered by URI template. A template provided by several backends is
+// listed once.
+func (rt *RoutingTable) GetResourceTemplates() []*mcp.ResourceTemplate {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	templates := make(map[string]*mcp.ResourceTemplate)
+	for _, route := range rt.templateRoutes {
+		if _, exists := templates[route.template.URITemplate]; !exists {
+			template := *route.template
+			templates[template.URITemplate] = &template
+		}
+	}
+
+	sorted := make([]*mcp.ResourceTemplate, 0, len(templates))
+	for _, uriTemplate := range slices.Sorted(maps.Keys(templates)) {
+		sorted = append(sorted, templates[uriTemplate])
+	}
+	return sorted
+}
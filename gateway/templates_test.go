@@ -0,0 +1,125 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+func TestRoutingTable_MatchesResourceTemplates(t *testing.T) {
+	rt := NewRoutingTable()
+	rt.setBackendResourceTemplates("files", []*mcp.ResourceTemplate{
+		{URITemplate: "file:///{+path}", Name: "files"},
+	})
+	rt.setBackendResourceTemplates("repo", []*mcp.ResourceTemplate{
+		{URITemplate: "file:///repo/{name}", Name: "repo"},
+		{URITemplate: "db://table/{table}", Name: "tables"},
+	})
+	rt.ResourcesMap["file:///repo/README.md"] = "docs"
+
+	tests := []struct {
+		uri      string
+		expected string
+	}{
+		{"file:///repo/x.go", "repo"},
+		{"file:///etc/hosts", "files"},
+		{"file:///repo/sub/y.go", "files"}, // {name} does not match "/"
+		{"db://table/users", "repo"},
+		{"file:///repo/README.md", "docs"}, // Listed resources win over templates
+	}
+	for _, tt := range tests {
+		if backend, exists := rt.FindResourceBackend(tt.uri); !exists || backend != tt.expected {
+			t.Errorf("Expected %s to route to %s, got %q", tt.uri, tt.expected, backend)
+		}
+	}
+	if backend, exists := rt.FindResourceBackend("http://example.com/"); exists {
+		t.Errorf("Expected no route for an unmatched URI, got %s", backend)
+	}
+
+	if templates := rt.GetResourceTemplates(); len(templates) != 3 || templates[0].URITemplate != "db://table/{table}" {
+		t.Errorf("Expected the templates of every backend ordered by template, got %+v", templates)
+	}
+
+	if !rt.setBackendResourceTemplates("repo", nil) {
+		t.Error("Expected removed templates to be reported as changed")
+	}
+	if backend, _ := rt.FindResourceBackend("file:///repo/x.go"); backend != "files" {
+		t.Errorf("Expected file:///repo/x.go to fall back to files, got %q", backend)
+	}
+}
+
+func TestGateway_RoutesResourceTemplates(t *testing.T) {
+	newBackend := func(name string, uriTemplates ...string) *httptest.Server {
+		server := mcp.NewServer(&mcp.Implementation{Name: name, Version: "1.0.0"}, nil)
+		for _, uriTemplate := range uriTemplates {
+			server.AddResourceTemplate(&mcp.ResourceTemplate{URITemplate: uriTemplate, Name: uriTemplate}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+				return &mcp.ReadResourceResult{
+					Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: name}},
+				}, nil
+			})
+		}
+		ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return server }, nil))
+		t.Cleanup(ts.Close)
+		return ts
+	}
+	files := newBackend("files", "file:///{+path}")
+	repo := newBackend("repo", "file:///repo/{name}", "db://table/{table}")
+
+	cfg := &config.Config{
+		Groups: []config.Group{
+			{
+				Name: "data",
+				Backends: map[string]config.Backend{
+					"files": {Name: "files", Transport: "http", Endpoint: files.URL},
+					"repo":  {Name: "repo", Transport: "http", Endpoint: repo.URL},
+				},
+			},
+		},
+	}
+
+	gateway, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	defer func() { _ = gateway.Close() }()
+
+	ctx := context.Background()
+	if err := gateway.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize gateway: %v", err)
+	}
+
+	session := connectTestClient(t, gateway.GetServer(), nil)
+	templates, err := session.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResourceTemplates failed: %v", err)
+	}
+	if len(templates.ResourceTemplates) != 3 {
+		t.Errorf("Expected the templates of both backends, got %+v", templates.ResourceTemplates)
+	}
+
+	tests := []struct {
+		uri      string
+		expected string
+	}{
+		{"file:///repo/x.go", "repo"},
+		{"file:///home/user/notes.txt", "files"},
+		{"db://table/users", "repo"},
+	}
+	for _, tt := range tests {
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: tt.uri})
+		if err != nil {
+			t.Fatalf("ReadResource(%s) failed: %v", tt.uri, err)
+		}
+		if len(result.Contents) != 1 || result.Contents[0].Text != tt.expected {
+			t.Errorf("Expected %s to be read from %s, got %+v", tt.uri, tt.expected, result.Contents)
+		}
+	}
+
+	if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "s3://bucket/key"}); err == nil {
+		t.Error("Expected reading a URI no template matches to fail")
+	}
+}
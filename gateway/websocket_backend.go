@@ -0,0 +1,364 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+const (
+	// websocketSubprotocol is the subprotocol offered when dialing MCP servers
+	websocketSubprotocol = "mcp"
+
+	// websocketReadLimit bounds the size of a single message from the backend
+	websocketReadLimit = 16 << 20
+
+	// websocketWriteTimeout bounds a single write; writes deliberately do not
+	// use the caller's context because cancelling it closes the connection
+	websocketWriteTimeout = 10 * time.Second
+
+	websocketInitialBackoff = time.Second
+	websocketMaxBackoff     = 30 * time.Second
+)
+
+// WebSocketBackend implements Backend interface for WebSocket transport.
+// A single persistent connection carries requests, responses and
+// server-initiated messages; the connection is re-established with backoff
+// when it drops.
+type WebSocketBackend struct {
+	messageDispatcher
+	restartNotifier
+	info       BackendInfo
+	config     config.Backend
+	endpoint   string
+	healthy    bool
+	mu         sync.RWMutex
+	connectMu  sync.Mutex
+	conn       *websocket.Conn
+	pending    *pendingRequests
+	initParams interface{}
+	closed     bool
+	closeCtx   context.Context
+	closeFunc  context.CancelFunc
+}
+
+// NewWebSocketBackend creates a new WebSocket backend
+func NewWebSocketBackend(cfg config.Backend, groupName string) *WebSocketBackend {
+	closeCtx, closeFunc := context.WithCancel(context.Background())
+	return &WebSocketBackend{
+		info: BackendInfo{
+			Name:      cfg.Name,
+			Transport: "websocket",
+			Group:     groupName,
+		},
+		config:    cfg,
+		endpoint:  cfg.Endpoint,
+		healthy:   true,
+		pending:   newPendingRequests(),
+		closeCtx:  closeCtx,
+		closeFunc: closeFunc,
+	}
+}
+
+func (b *WebSocketBackend) Initialize(ctx context.Context, req interface{}) (*mcp.InitializeResult, error) {
+	ctx, cancel := withRequestTimeout(ctx, b.RequestTimeout())
+	defer cancel()
+
+	b.mu.Lock()
+	b.initParams = req
+	b.mu.Unlock()
+
+	if err := b.connect(ctx); err != nil {
+		b.SetHealthy(false)
+		return nil, err
+	}
+
+	result, err := b.initialize(ctx, req)
+	if err != nil {
+		b.SetHealthy(false)
+		return nil, err
+	}
+
+	b.SetHealthy(true)
+	return result, nil
+}
+
+// initialize performs the initialize handshake on the current connection
+func (b *WebSocketBackend) initialize(ctx context.Context, req interface{}) (*mcp.InitializeResult, error) {
+	response, err := b.sendJSONRPC(ctx, "initialize", req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *mcp.InitializeResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal initialize response: %w", err)
+	}
+
+	if err := b.notify("notifications/initialized", struct{}{}); err != nil {
+		return nil, fmt.Errorf("failed to send initialized notification: %w", err)
+	}
+
+	return result, nil
+}
+
+// connect dials the backend unless a connection is already open
+func (b *WebSocketBackend) connect(ctx context.Context) error {
+	b.connectMu.Lock()
+	defer b.connectMu.Unlock()
+
+	b.mu.RLock()
+	conn, closed := b.conn, b.closed
+	b.mu.RUnlock()
+
+	if closed {
+		return fmt.Errorf("backend %s is closed", b.info.Name)
+	}
+	if conn != nil {
+		return nil // Already connected
+	}
+
+	header := http.Header{}
+	for key, value := range b.config.Headers {
+		header.Set(key, value)
+	}
+
+	conn, _, err := websocket.Dial(ctx, b.endpoint, &websocket.DialOptions{
+		HTTPHeader:   header,
+		Subprotocols: []string{websocketSubprotocol},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to websocket: %w", err)
+	}
+	conn.SetReadLimit(websocketReadLimit)
+
+	b.mu.Lock()
+	b.conn = conn
+	b.mu.Unlock()
+
+	go b.readLoop(conn)
+
+	return nil
+}
+
+// readLoop reads messages from the connection and hands each response to
+// the request waiting for its id. When the connection drops, it fails all
+// pending requests and starts reconnecting.
+func (b *WebSocketBackend) readLoop(conn *websocket.Conn) {
+	for {
+		_, data, err := conn.Read(b.closeCtx)
+		if err != nil {
+			// Only a connection that dropped on its own triggers a reconnect;
+			// connections replaced or closed by us are already accounted for
+			b.mu.Lock()
+			lost := b.conn == conn && !b.closed
+			if lost {
+				b.conn = nil
+				b.healthy = false
+			}
+			b.mu.Unlock()
+
+			if lost {
+				b.pending.failAll()
+				slog.Warn("Websocket connection lost", "backend", b.info.Name, "error", err)
+				go b.reconnectLoop()
+			}
+			return
+		}
+
+		messages, err := decodeJSONRPCMessages(data)
+		if err != nil {
+			slog.Warn("Invalid message on websocket", "backend", b.info.Name, "error", err)
+			continue
+		}
+
+		for i := range messages {
+			if messages[i].isResponse() {
+				if !b.pending.deliver(&messages[i]) {
+					slog.Warn("Dropping response with unknown id", "backend", b.info.Name, "jsonrpc_id", messages[i].idKey())
+				}
+				continue
+			}
+			b.dispatch(b.closeCtx, b.info.Name, &messages[i], b.reply)
+		}
+	}
+}
+
+// reconnectLoop re-establishes the connection with exponential backoff and
+// repeats the initialize handshake, since the new connection is a new session
+func (b *WebSocketBackend) reconnectLoop() {
+	backoff := websocketInitialBackoff
+
+	for {
+		select {
+		case <-b.closeCtx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		b.mu.RLock()
+		initParams := b.initParams
+		b.mu.RUnlock()
+
+		ctx, cancel := context.WithTimeout(b.closeCtx, 30*time.Second)
+		var result *mcp.InitializeResult
+		err := b.connect(ctx)
+		if err == nil {
+			result, err = b.initialize(ctx, initParams)
+		}
+		cancel()
+
+		if err == nil {
+			slog.Info("Websocket reconnected", "backend", b.info.Name)
+			b.SetHealthy(true)
+
+			ctx, cancel := context.WithTimeout(b.closeCtx, restartHandlerTimeout)
+			b.notifyRestart(ctx, result)
+			cancel()
+			return
+		}
+
+		slog.Warn("Websocket reconnect failed", "backend", b.info.Name, "error", err)
+		b.dropConnection()
+
+		backoff *= 2
+		if backoff > websocketMaxBackoff {
+			backoff = websocketMaxBackoff
+		}
+	}
+}
+
+// dropConnection closes the current connection without triggering a reconnect
+func (b *WebSocketBackend) dropConnection() {
+	b.mu.Lock()
+	conn := b.conn
+	b.conn = nil
+	b.mu.Unlock()
+
+	if conn != nil {
+		_ = conn.Close(websocket.StatusGoingAway, "reconnecting")
+	}
+}
+
+func (b *WebSocketBackend) SendRequest(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
+	ctx, cancel := withRequestTimeout(ctx, b.RequestTimeout())
+	defer cancel()
+	return b.sendJSONRPC(ctx, method, params)
+}
+
+// RequestTimeout implements TimeoutProvider
+func (b *WebSocketBackend) RequestTimeout() time.Duration {
+	return requestTimeout(b.config)
+}
+
+func (b *WebSocketBackend) sendJSONRPC(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
+	id, responseCh := b.pending.register()
+
+	jsonData, err := json.Marshal(newJSONRPCRequest(id, method, params))
+	if err != nil {
+		b.pending.remove(id)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	if err := b.write(jsonData); err != nil {
+		b.pending.remove(id)
+		b.SetHealthy(false)
+		return nil, err
+	}
+
+	// Wait for the reader goroutine to deliver the matching response
+	select {
+	case response, ok := <-responseCh:
+		if !ok {
+			return nil, fmt.Errorf("websocket connection closed before response: %w", io.ErrUnexpectedEOF)
+		}
+		result, err := response.resultOrError()
+		if err != nil {
+			return nil, err
+		}
+		b.SetHealthy(true)
+		return result, nil
+	case <-ctx.Done():
+		b.pending.remove(id)
+		cancelRequest(b.info.Name, b.reply, id, method, ctx.Err())
+		return nil, ctx.Err()
+	}
+}
+
+// notify sends a JSON-RPC notification to the backend
+func (b *WebSocketBackend) notify(method string, params interface{}) error {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	return b.write(jsonData)
+}
+
+// write sends a single text message on the current connection
+func (b *WebSocketBackend) write(data []byte) error {
+	b.mu.RLock()
+	conn := b.conn
+	b.mu.RUnlock()
+
+	if conn == nil {
+		return fmt.Errorf("backend %s is not connected", b.info.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(b.closeCtx, websocketWriteTimeout)
+	defer cancel()
+
+	if err := conn.Write(ctx, websocket.MessageText, data); err != nil {
+		return fmt.Errorf("failed to write to websocket: %w", err)
+	}
+	return nil
+}
+
+// reply sends our response to a request initiated by the backend
+func (b *WebSocketBackend) reply(data []byte) error {
+	return b.write(data)
+}
+
+func (b *WebSocketBackend) GetInfo() BackendInfo {
+	return b.info
+}
+
+func (b *WebSocketBackend) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	conn := b.conn
+	b.conn = nil
+	b.mu.Unlock()
+
+	b.closeFunc()
+	b.pending.failAll()
+
+	if conn != nil {
+		_ = conn.Close(websocket.StatusNormalClosure, "")
+	}
+	return nil
+}
+
+func (b *WebSocketBackend) IsHealthy() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.healthy
+}
+
+func (b *WebSocketBackend) SetHealthy(healthy bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.healthy = healthy
+}
@@ -0,0 +1,320 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/takutakahashi/awesome-mcp-proxy/config"
+)
+
+// websocketTestServer is a minimal MCP server speaking JSON-RPC over WebSocket
+type websocketTestServer struct {
+	*httptest.Server
+	connections atomic.Int32
+	initialized atomic.Int32
+	cancelled   chan json.RawMessage // ids of the requests the client cancelled
+}
+
+// newWebSocketTestServer starts a server that answers initialize, ping and
+// tools/call. A call with the "notify" argument sends a log notification
+// first, a call with the "drop" argument closes the connection and a call
+// with the "hang" argument is never answered.
+func newWebSocketTestServer(t *testing.T) *websocketTestServer {
+	t.Helper()
+
+	ts := &websocketTestServer{cancelled: make(chan json.RawMessage, 10)}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{websocketSubprotocol}})
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.CloseNow() }()
+		ts.connections.Add(1)
+
+		ctx := r.Context()
+		var writeMu sync.Mutex
+		write := func(msg interface{}) {
+			data, _ := json.Marshal(msg)
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			_ = conn.Write(ctx, websocket.MessageText, data)
+		}
+
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+
+			var request struct {
+				ID     *json.RawMessage `json:"id"`
+				Method string           `json:"method"`
+				Params struct {
+					Arguments map[string]interface{} `json:"arguments"`
+					RequestID json.RawMessage        `json:"requestId"`
+				} `json:"params"`
+			}
+			if err := json.Unmarshal(data, &request); err != nil {
+				continue
+			}
+			if request.ID == nil {
+				if request.Method == "notifications/cancelled" {
+					ts.cancelled <- request.Params.RequestID
+				}
+				continue
+			}
+
+			response := map[string]interface{}{"jsonrpc": "2.0", "id": request.ID}
+			switch request.Method {
+			case "initialize":
+				ts.initialized.Add(1)
+				response["result"] = map[string]interface{}{
+					"protocolVersion": "2025-06-18",
+					"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+					"serverInfo":      map[string]interface{}{"name": "websocket-server", "version": "1.0.0"},
+				}
+			case "ping":
+				response["result"] = map[string]interface{}{}
+			case "tools/call":
+				if _, ok := request.Params.Arguments["drop"]; ok {
+					_ = conn.Close(websocket.StatusGoingAway, "dropping")
+					return
+				}
+				if _, ok := request.Params.Arguments["hang"]; ok {
+					continue
+				}
+				if notify, ok := request.Params.Arguments["notify"].(string); ok {
+					write(map[string]interface{}{
+						"jsonrpc": "2.0",
+						"method":  "notifications/message",
+						"params":  map[string]interface{}{"level": "info", "data": notify},
+					})
+				}
+				value, _ := request.Params.Arguments["value"].(string)
+				response["result"] = map[string]interface{}{
+					"content": []map[string]interface{}{{"type": "text", "text": value}},
+				}
+			default:
+				response["error"] = map[string]interface{}{"code": -32601, "message": "Method not found"}
+			}
+
+			// Answer concurrently so responses may arrive out of order
+			go write(response)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newTestWebSocketBackend(t *testing.T, ts *websocketTestServer) *WebSocketBackend {
+	t.Helper()
+
+	backend := NewWebSocketBackend(config.Backend{
+		Name:      "websocket-backend",
+		Transport: "websocket",
+		Endpoint:  "ws" + strings.TrimPrefix(ts.URL, "http"),
+	}, "test-group")
+	t.Cleanup(func() { _ = backend.Close() })
+	return backend
+}
+
+func TestWebSocketBackend_InitializeAndCall(t *testing.T) {
+	ts := newWebSocketTestServer(t)
+	backend := newTestWebSocketBackend(t, ts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := backend.Initialize(ctx, testInitParams())
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if result.ServerInfo == nil || result.ServerInfo.Name != "websocket-server" {
+		t.Errorf("Unexpected server info: %+v", result.ServerInfo)
+	}
+	if !backend.IsHealthy() {
+		t.Error("Backend should be healthy after successful initialization")
+	}
+
+	// Concurrent calls are correlated by id on the shared connection
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value := fmt.Sprintf("call-%d", i)
+			response, err := backend.SendRequest(ctx, "tools/call", map[string]interface{}{
+				"name":      "echo",
+				"arguments": map[string]interface{}{"value": value},
+			})
+			if err != nil {
+				t.Errorf("Call %d failed: %v", i, err)
+				return
+			}
+			if !strings.Contains(string(*response), value) {
+				t.Errorf("Call %d got response for another request: %s", i, string(*response))
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestWebSocketBackend_DispatchesNotifications(t *testing.T) {
+	ts := newWebSocketTestServer(t)
+	backend := newTestWebSocketBackend(t, ts)
+	handler := &recordingMessageHandler{}
+	backend.SetMessageHandler(handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := backend.Initialize(ctx, testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	if _, err := backend.SendRequest(ctx, "tools/call", map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"notify": "working"},
+	}); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.notifications) != 1 || !strings.Contains(handler.notifications[0], "websocket-backend notifications/message") {
+		t.Errorf("Unexpected notifications: %v", handler.notifications)
+	}
+}
+
+func TestWebSocketBackend_ReconnectsAfterDrop(t *testing.T) {
+	ts := newWebSocketTestServer(t)
+	backend := newTestWebSocketBackend(t, ts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := backend.Initialize(ctx, testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	// The pending call fails when the server drops the connection
+	if _, err := backend.SendRequest(ctx, "tools/call", map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"drop": true},
+	}); err == nil {
+		t.Fatal("Expected call to fail when the connection drops")
+	}
+
+	// The backend reconnects and repeats the handshake in the background
+	deadline := time.Now().Add(5 * time.Second)
+	for !backend.IsHealthy() || ts.initialized.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Backend did not reconnect: connections=%d initialized=%d", ts.connections.Load(), ts.initialized.Load())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	response, err := backend.SendRequest(ctx, "tools/call", map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"value": "after-reconnect"},
+	})
+	if err != nil {
+		t.Fatalf("Call after reconnect failed: %v", err)
+	}
+	if !strings.Contains(string(*response), "after-reconnect") {
+		t.Errorf("Unexpected response: %s", string(*response))
+	}
+}
+
+func TestWebSocketBackend_CancelsAbandonedRequests(t *testing.T) {
+	ts := newWebSocketTestServer(t)
+	backend := newTestWebSocketBackend(t, ts)
+
+	if _, err := backend.Initialize(context.Background(), testInitParams()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	_, err := backend.SendRequest(ctx, "tools/call", map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"hang": true},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the request to be cancelled, got %v", err)
+	}
+
+	select {
+	case id := <-ts.cancelled:
+		if len(id) == 0 {
+			t.Error("Expected notifications/cancelled to carry the request id")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for notifications/cancelled")
+	}
+}
+
+func TestWebSocketBackend_InitializeTimeout(t *testing.T) {
+	// A server that accepts the connection but never answers
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{websocketSubprotocol}})
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.CloseNow() }()
+		for {
+			if _, _, err := conn.Read(r.Context()); err != nil {
+				return
+			}
+		}
+	}))
+	defer ts.Close()
+
+	backend := NewWebSocketBackend(config.Backend{
+		Name:      "websocket-backend",
+		Transport: "websocket",
+		Endpoint:  "ws" + strings.TrimPrefix(ts.URL, "http"),
+		Timeout:   100 * time.Millisecond,
+	}, "test-group")
+	defer func() { _ = backend.Close() }()
+
+	start := time.Now()
+	if _, err := backend.Initialize(context.Background(), testInitParams()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the handshake to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Handshake was not abandoned at the timeout, took %v", elapsed)
+	}
+}
+
+func TestWebSocketBackend_InitializeFailsWithoutServer(t *testing.T) {
+	backend := NewWebSocketBackend(config.Backend{
+		Name:      "websocket-backend",
+		Transport: "websocket",
+		Endpoint:  "ws://127.0.0.1:1/mcp",
+	}, "test-group")
+	defer func() { _ = backend.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := backend.Initialize(ctx, testInitParams()); err == nil {
+		t.Fatal("Expected Initialize to fail without a server")
+	}
+	if backend.IsHealthy() {
+		t.Error("Backend should be unhealthy after failed initialization")
+	}
+}
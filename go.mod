@@ -3,8 +3,10 @@ module github.com/takutakahashi/awesome-mcp-proxy
 go 1.24.7
 
 require (
+	github.com/coder/websocket v1.8.14
 	github.com/modelcontextprotocol/go-sdk v1.1.0
 	github.com/spf13/viper v1.21.0
+	github.com/yosida95/uritemplate/v3 v3.0.2
 )
 
 require (
@@ -18,7 +20,6 @@ require (
 	github.com/spf13/cast v1.10.0 // indirect
 	github.com/spf13/pflag v1.0.10 // indirect
 	github.com/subosito/gotenv v1.6.0 // indirect
-	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
 	go.yaml.in/yaml/v3 v3.0.4 // indirect
 	golang.org/x/oauth2 v0.30.0 // indirect
 	golang.org/x/sys v0.34.0 // indirect
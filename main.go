@@ -4,8 +4,11 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"slices"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -28,14 +31,22 @@ func main() {
 }
 
 func runGateway(addr, configPath string) {
-	log.Printf("Starting MCP Gateway with config: %s", configPath)
+	slog.Info("Starting MCP Gateway", "config", configPath)
 
 	// Load configuration
 	cfg, err := config.LoadConfig(configPath)
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fatal("Failed to load config", "error", err)
 	}
 
+	// Log as configured from here on; the standard logger goes along
+	logger, closeLog, err := gateway.NewLogger(cfg.Middleware.Logging)
+	if err != nil {
+		fatal("Failed to set up logging", "error", err)
+	}
+	defer func() { _ = closeLog() }()
+	slog.SetDefault(logger)
+
 	// Use configured host and port if provided
 	if cfg.Gateway.Host != "" && cfg.Gateway.Port != 0 {
 		addr = fmt.Sprintf("%s:%d", cfg.Gateway.Host, cfg.Gateway.Port)
@@ -44,7 +55,7 @@ func runGateway(addr, configPath string) {
 	// Create gateway
 	gatewayServer, err := gateway.NewGateway(cfg)
 	if err != nil {
-		log.Fatalf("Failed to create gateway: %v", err)
+		fatal("Failed to create gateway", "error", err)
 	}
 	defer func() { _ = gatewayServer.Close() }()
 
@@ -53,7 +64,33 @@ func runGateway(addr, configPath string) {
 	defer cancel()
 
 	if err := gatewayServer.Initialize(ctx); err != nil {
-		log.Fatalf("Failed to initialize gateway: %v", err)
+		fatal("Failed to initialize gateway", "error", err)
+	}
+
+	// Require clients to authenticate if configured
+	protect := func(handler http.Handler) http.Handler { return handler }
+	if cfg.Middleware.Auth.Enabled {
+		authenticator, err := gateway.NewAuthenticator(cfg.Middleware.Auth)
+		if err != nil {
+			fatal("Failed to set up authentication", "error", err)
+		}
+		protect = authenticator.Middleware
+		slog.Info("Client authentication enabled")
+	} else {
+		slog.Warn("Client authentication is disabled; do not expose the gateway beyond localhost")
+	}
+
+	// Let browser-based clients on allowed origins connect; CORS runs first
+	// because preflight requests carry no credentials
+	mcpHandler := protect
+	if cfg.Middleware.CORS.Enabled {
+		origins := cfg.CORSOrigins()
+		if slices.Contains(origins, "*") && cfg.Middleware.Auth.Enabled {
+			slog.Warn("CORS allows any origin although client authentication is enabled")
+		}
+		cors := gateway.NewCORS(origins)
+		mcpHandler = func(handler http.Handler) http.Handler { return cors.Middleware(protect(handler)) }
+		slog.Info("CORS enabled", "origins", origins)
 	}
 
 	// Create HTTP handlers
@@ -72,19 +109,39 @@ func runGateway(addr, configPath string) {
 	)
 
 	// Set up HTTP server
-	http.Handle("/mcp", streamHandler)
-	http.Handle("/sse", sseHandler)
+	http.Handle(cfg.Gateway.Endpoint, mcpHandler(streamHandler))
+	http.Handle("/sse", mcpHandler(sseHandler))
+	// Backend stderr may contain credentials; without authentication it is
+	// only served to local clients
+	if cfg.Middleware.Auth.Enabled || loopbackAddr(addr) {
+		http.Handle("/admin/", protect(gatewayServer.AdminHandler()))
+	} else {
+		slog.Warn("Admin endpoints are disabled because client authentication is disabled and the gateway listens beyond localhost", "addr", addr)
+	}
+
+	// Serve each group on its own path, exposing only the group's backends
+	for _, group := range cfg.Groups {
+		groupServer := gatewayServer.GetGroupServer(group.Name)
+		getServer := func(r *http.Request) *mcp.Server {
+			return groupServer
+		}
+
+		http.Handle(cfg.Gateway.Endpoint+"/"+group.Name, mcpHandler(mcp.NewStreamableHTTPHandler(getServer, nil)))
+		http.Handle("/sse/"+group.Name, mcpHandler(mcp.NewSSEHandler(getServer, nil)))
+		slog.Info("Group endpoint available", "group", group.Name, "addr", addr, "endpoint", cfg.Gateway.Endpoint+"/"+group.Name)
+	}
 
-	log.Printf("MCP Gateway starting on %s/mcp", addr)
-	log.Printf("Capabilities: %+v", gatewayServer.GetCapabilities())
+	capabilities := gatewayServer.GetCapabilities()
+	slog.Info("MCP Gateway starting", "addr", addr, "endpoint", cfg.Gateway.Endpoint,
+		"tools", capabilities.Tools, "resources", capabilities.Resources, "prompts", capabilities.Prompts)
 
 	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatalf("Server error: %v", err)
+		fatal("Server error", "error", err)
 	}
 }
 
 func runStandaloneServer(addr string) {
-	log.Println("Starting standalone MCP Server")
+	slog.Info("Starting standalone MCP Server")
 
 	// Create MCP server
 	mcpServer := mcpserver.NewMCPServer()
@@ -109,10 +166,30 @@ func runStandaloneServer(addr string) {
 	http.Handle("/mcp", streamHandler)
 	http.Handle("/sse", sseHandler)
 
-	log.Printf("MCP HTTP Server starting on %s/mcp", addr)
-	log.Printf("Using official MCP Go SDK with Streamable HTTP transport")
+	slog.Info("MCP HTTP Server starting", "addr", addr, "endpoint", "/mcp")
+	slog.Info("Using official MCP Go SDK with Streamable HTTP transport")
 
 	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatalf("Server error: %v", err)
+		fatal("Server error", "error", err)
 	}
 }
+
+// loopbackAddr reports whether a listen address only accepts connections
+// from the local host
+func loopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// fatal logs an error and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
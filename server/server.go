@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -86,7 +86,7 @@ func (s *MCPServer) registerTools() {
 		}, nil, nil
 	})
 
-	log.Println("Registered tools: echo, add")
+	slog.Info("Registered tools", "tools", []string{"echo", "add"})
 }
 
 // registerResources registers example resources
@@ -136,7 +136,7 @@ func (s *MCPServer) registerResources() {
 		}, nil
 	})
 
-	log.Println("Registered resources: info://server, status://health")
+	slog.Info("Registered resources", "resources", []string{"info://server", "status://health"})
 }
 
 // Prompt parameter types
@@ -171,7 +171,7 @@ func (s *MCPServer) registerPrompts() {
 		}, nil
 	})
 
-	log.Println("Registered prompts: greeting")
+	slog.Info("Registered prompts", "prompts", []string{"greeting"})
 }
 
 // GetServer returns the underlying MCP server